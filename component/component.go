@@ -6,15 +6,16 @@ package component
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"unicode"
 
+	"github.com/gopherd/core/errkit"
 	"github.com/gopherd/core/lifecycle"
 	"github.com/gopherd/core/types"
 )
@@ -358,6 +359,65 @@ func getResolver(field reflect.Value) Resolver {
 	return nil
 }
 
+// PanicError is the error reported when a lifecycle method of a component panics.
+type PanicError struct {
+	// Component is the identifier of the panicking component. It's empty if the
+	// panic did not occur in a component.
+	Component string
+	// Phase is the lifecycle phase, one of "init", "start", "shutdown" and "uninit".
+	Phase string
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	if e.Component == "" {
+		return fmt.Sprintf("panic during %s: %v", e.Phase, e.Value)
+	}
+	return fmt.Sprintf("component %s panicked during %s: %v", e.Component, e.Phase, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// LogValue implements the slog.LogValuer interface.
+func (e *PanicError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("component", e.Component),
+		slog.String("phase", e.Phase),
+		slog.Any("panic", e.Value),
+		slog.String("stack", string(e.Stack)),
+	)
+}
+
+// Recover calls fn and converts a panic into a PanicError with the phase.
+func Recover(ctx context.Context, phase string, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Phase: phase, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// safeCall calls the lifecycle method fn of the component like Recover and
+// records the component in the PanicError.
+func safeCall(ctx context.Context, com Component, phase string, fn func(context.Context) error) error {
+	err := Recover(ctx, phase, fn)
+	if e, ok := err.(*PanicError); ok && e.Component == "" {
+		e.Component = com.String()
+	}
+	return err
+}
+
 // Group manages a group of components.
 type Group struct {
 	components      []Component
@@ -391,12 +451,13 @@ func (g *Group) GetComponent(uuid string) Component {
 	return g.uuidToComponent[uuid]
 }
 
-// Init initializes all components in the group.
+// Init initializes all components in the group. A panic in a component is
+// recovered and reported as a *PanicError.
 func (g *Group) Init(ctx context.Context) error {
 	for i := range g.components {
 		com := g.components[i]
 		com.Logger().Info("initializing component")
-		if err := com.Init(ctx); err != nil {
+		if err := safeCall(ctx, com, "init", com.Init); err != nil {
 			com.Logger().Error("failed to initialize component", "error", err)
 			return err
 		}
@@ -406,26 +467,32 @@ func (g *Group) Init(ctx context.Context) error {
 	return nil
 }
 
-// Uninit uninitializes all components in reverse order.
+// Uninit uninitializes all components in reverse order. Unlike Init, it
+// doesn't stop at the first error: every initialized component is
+// uninitialized even if others fail or panic, so that none of them is left
+// holding its resources, and the errors are aggregated in an errkit.MultiError.
 func (g *Group) Uninit(ctx context.Context) error {
+	var errs []error
 	for i := g.numInitialized - 1; i >= 0; i-- {
 		com := g.components[i]
 		com.Logger().Info("uninitializing component")
-		if err := com.Uninit(ctx); err != nil {
+		if err := safeCall(ctx, com, "uninit", com.Uninit); err != nil {
 			com.Logger().Error("failed to uninitialize component", "error", err)
-			return err
+			errs = append(errs, errkit.NewWithContext(errkit.Errno(err), err, "uninit "+com.String()))
+		} else {
+			com.Logger().Info("component uninitialized")
 		}
-		com.Logger().Info("component uninitialized")
 	}
-	return nil
+	return errkit.Join(errs...)
 }
 
-// Start starts all components in the group.
+// Start starts all components in the group. A panic in a component is
+// recovered and reported as a *PanicError.
 func (g *Group) Start(ctx context.Context) error {
 	for i := range g.components {
 		com := g.components[i]
 		com.Logger().Info("starting component")
-		if err := com.Start(ctx); err != nil {
+		if err := safeCall(ctx, com, "start", com.Start); err != nil {
 			com.Logger().Error("failed to start component", "error", err)
 			return err
 		}
@@ -435,20 +502,22 @@ func (g *Group) Start(ctx context.Context) error {
 	return nil
 }
 
-// Shutdown shuts down all components in reverse order.
+// Shutdown shuts down all components in reverse order. It shuts down every
+// component even if some of them fail or panic and returns an errkit.MultiError
+// which aggregates the errors.
 func (g *Group) Shutdown(ctx context.Context) error {
 	var errs []error
 	for i := g.numStarted - 1; i >= 0; i-- {
 		com := g.components[i]
 		com.Logger().Info("shutting down component")
-		if err := com.Shutdown(ctx); err != nil {
+		if err := safeCall(ctx, com, "shutdown", com.Shutdown); err != nil {
 			com.Logger().Error("failed to shutdown component", "error", err)
-			errs = append(errs, err)
+			errs = append(errs, errkit.NewWithContext(errkit.Errno(err), err, "shutdown "+com.String()))
 		} else {
 			com.Logger().Info("component shutdown")
 		}
 	}
-	return errors.Join(errs...)
+	return errkit.Join(errs...)
 }
 
 var (
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/gopherd/core/component"
+	"github.com/gopherd/core/errkit"
 	"github.com/gopherd/core/op"
 	"github.com/gopherd/core/types"
 )
@@ -454,6 +455,143 @@ func TestGroup(t *testing.T) {
 			t.Error("Expected error during group uninit, got nil")
 		}
 	})
+
+	t.Run("Aggregated errors", func(t *testing.T) {
+		group := component.NewGroup()
+		errFirst := errkit.New(1001, errors.New("first failed"))
+		errSecond := errors.New("second failed")
+		for i, err := range []error{errFirst, errSecond} {
+			com := &erroringComponent{err: err}
+			uuid := fmt.Sprintf("erroring-%d", i)
+			com.Setup(newMockContainer(), &component.Config{Name: "ErroringComponent", UUID: uuid}, false)
+			group.AddComponent(uuid, com)
+		}
+		ctx := context.Background()
+		if err := group.Init(ctx); err != nil {
+			t.Fatalf("Unexpected error during group initialization: %v", err)
+		}
+		if err := group.Start(ctx); err != nil {
+			t.Fatalf("Unexpected error during group start: %v", err)
+		}
+		for _, tt := range []struct {
+			phase string
+			fn    func(context.Context) error
+		}{
+			{"shutdown", group.Shutdown},
+			{"uninit", group.Uninit},
+		} {
+			err := tt.fn(ctx)
+			errs := errkit.Errors(err)
+			if len(errs) != 2 {
+				t.Fatalf("Expected 2 %s errors, got %v", tt.phase, err)
+			}
+			if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+				t.Errorf("Expected %s error to match both errors, got %v", tt.phase, err)
+			}
+			// components are processed in reverse order
+			if code := errkit.Errno(errs[1]); code != 1001 {
+				t.Errorf("Expected %s error code 1001, got %d", tt.phase, code)
+			}
+			if want := tt.phase + " ErroringComponent#erroring-0: first failed"; errs[1].Error() != want {
+				t.Errorf("Expected %q, got %q", want, errs[1].Error())
+			}
+		}
+	})
+}
+
+type panickingComponent struct {
+	component.BaseComponent[struct{}]
+	phase    string
+	shutdown bool
+}
+
+func (c *panickingComponent) maybePanic(phase string) error {
+	if c.phase == phase {
+		panic("panic in " + phase)
+	}
+	return nil
+}
+
+func (c *panickingComponent) Init(ctx context.Context) error   { return c.maybePanic("init") }
+func (c *panickingComponent) Start(ctx context.Context) error  { return c.maybePanic("start") }
+func (c *panickingComponent) Uninit(ctx context.Context) error { return c.maybePanic("uninit") }
+func (c *panickingComponent) Shutdown(ctx context.Context) error {
+	c.shutdown = true
+	return c.maybePanic("shutdown")
+}
+
+func TestGroupPanic(t *testing.T) {
+	for _, phase := range []string{"init", "start", "shutdown", "uninit"} {
+		t.Run(phase, func(t *testing.T) {
+			group := component.NewGroup()
+			first := &panickingComponent{}
+			second := &panickingComponent{phase: phase}
+			first.Setup(newMockContainer(), &component.Config{Name: "First", UUID: "first"}, false)
+			second.Setup(newMockContainer(), &component.Config{Name: "Second", UUID: "second"}, false)
+			group.AddComponent("first", first)
+			group.AddComponent("second", second)
+
+			ctx := context.Background()
+			errs := []error{group.Init(ctx), group.Start(ctx), group.Shutdown(ctx), group.Uninit(ctx)}
+			var panicked int
+			for _, err := range errs {
+				var pe *component.PanicError
+				if !errors.As(err, &pe) {
+					continue
+				}
+				panicked++
+				if pe.Component != second.String() || pe.Phase != phase || pe.Value != "panic in "+phase {
+					t.Errorf("Unexpected panic error: %v", pe)
+				}
+				if len(pe.Stack) == 0 {
+					t.Errorf("Expected stack in panic error")
+				}
+			}
+			if panicked != 1 {
+				t.Errorf("Expected exactly one panic error, got %v", errs)
+			}
+			if phase != "init" && !first.shutdown {
+				t.Errorf("Expected first component to be shut down")
+			}
+		})
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	cause := errors.New("cause")
+	pe := &component.PanicError{Component: "C#1", Phase: "start", Value: cause}
+	if pe.Error() != "component C#1 panicked during start: cause" {
+		t.Errorf("Unexpected error message: %s", pe.Error())
+	}
+	if !errors.Is(pe, cause) {
+		t.Errorf("Expected panic error to wrap the panic value")
+	}
+	if (&component.PanicError{Phase: "init", Value: 1}).Unwrap() != nil {
+		t.Errorf("Expected nil unwrap for non-error panic value")
+	}
+
+	err := component.Recover(context.Background(), "init", func(context.Context) error {
+		panic("oops")
+	})
+	if !errors.As(err, &pe) || pe.Component != "" || err.Error() != "panic during init: oops" {
+		t.Errorf("Unexpected recovered error: %v", err)
+	}
+	if err := component.Recover(context.Background(), "init", func(context.Context) error { return nil }); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
+
+type erroringComponent struct {
+	component.BaseComponent[struct{}]
+	err error
+}
+
+func (c *erroringComponent) Shutdown(ctx context.Context) error {
+	return c.err
+}
+
+func (c *erroringComponent) Uninit(ctx context.Context) error {
+	return c.err
 }
 
 func testGroupLifecycle(t *testing.T, group *component.Group, ctx context.Context) {
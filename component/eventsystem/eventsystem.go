@@ -0,0 +1,142 @@
+// Package eventsystem provides a component which owns an event system shared by
+// other components.
+//
+// Components reference the shared event system in their Refs:
+//
+//	type myComponent struct {
+//		component.BaseComponentWithRefs[myOptions, struct {
+//			Events component.Reference[eventsystem.API[string]]
+//		}]
+//	}
+//
+//	func (c *myComponent) Init(ctx context.Context) error {
+//		c.Refs().Events.Component().AddListener(event.Listen("user.login", c.onLogin))
+//		return nil
+//	}
+//
+// The event system is created during Setup, so listeners can be added in Init
+// regardless of the component order. The event system component should be
+// configured before the components referencing it. Components are shut down in
+// reverse order and uninitialized after all of them have been shut down, so the
+// shutdown proceeds as follows:
+//
+//  1. Dependents are shut down first and can still dispatch events meanwhile.
+//  2. The event system stops accepting events and drains its pending events,
+//     within DrainTimeout if set. Listeners of dependents therefore receive
+//     events after their component has been shut down, and must keep handling
+//     them until Uninit.
+//  3. Components are uninitialized, none of them before the events are drained.
+package eventsystem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopherd/core/component"
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/types"
+)
+
+// Name is the name under which the event system component with string event
+// types is registered.
+const Name = "github.com/gopherd/core/component/eventsystem"
+
+func init() {
+	Register[string](Name)
+}
+
+// API is the interface of the event system component.
+type API[T comparable] interface {
+	event.ConcurrentEventSystem[T]
+}
+
+// Options represents the options of the event system component.
+type Options struct {
+	// Async enables asynchronous dispatch backed by a queue.
+	Async bool
+	// Ordered handles asynchronous events in dispatch order by using a single worker.
+	Ordered bool
+	// QueueSize is the size of the asynchronous queue.
+	QueueSize int
+	// Workers is the number of goroutines handling asynchronous events.
+	// It is ignored if Ordered is set.
+	Workers int
+	// Backpressure is the policy applied when the asynchronous queue is full:
+	// "block" (default), "drop" or "error".
+	Backpressure event.BackpressurePolicy `json:",omitempty"`
+	// DispatchMode is "collect-all" (default) or "fail-fast".
+	DispatchMode event.DispatchMode `json:",omitempty"`
+	// DrainTimeout limits how long Shutdown waits for pending events. Zero means no limit.
+	DrainTimeout types.Duration `json:",omitempty"`
+}
+
+// OnLoaded implements the options loaded hook and validates the options.
+func (o *Options) OnLoaded() error {
+	if o.QueueSize < 0 {
+		return fmt.Errorf("invalid queue size %d", o.QueueSize)
+	}
+	if o.Workers < 0 {
+		return fmt.Errorf("invalid number of workers %d", o.Workers)
+	}
+	if o.DrainTimeout < 0 {
+		return fmt.Errorf("invalid drain timeout %v", o.DrainTimeout.Value())
+	}
+	return nil
+}
+
+// Component is a component which owns an event.ConcurrentEventSystem.
+type Component[T comparable] struct {
+	component.BaseComponent[Options]
+	event.ConcurrentEventSystem[T]
+}
+
+var _ API[string] = (*Component[string])(nil)
+
+// Register registers an event system component with event types of type T by the provided name.
+func Register[T comparable](name string) {
+	component.Register(name, func() component.Component {
+		return new(Component[T])
+	})
+}
+
+// Setup implements the component.Component Setup method and creates the event system.
+func (c *Component[T]) Setup(container component.Container, config *component.Config, rewrite bool) error {
+	if err := c.BaseComponent.Setup(container, config, rewrite); err != nil {
+		return err
+	}
+	options := c.Options()
+	opts := []event.ConcurrentOption{
+		event.WithDispatchMode(options.DispatchMode),
+		event.WithErrorHandler(func(ctx context.Context, err error) {
+			c.Logger().Warn("failed to handle event", "error", err)
+		}),
+	}
+	if options.Async {
+		workers := options.Workers
+		if options.Ordered || workers == 0 {
+			workers = 1
+		}
+		opts = append(opts,
+			event.WithAsync(options.QueueSize, workers),
+			event.WithBackpressure(options.Backpressure),
+		)
+	}
+	c.ConcurrentEventSystem = event.NewConcurrentEventSystem[T](opts...)
+	return nil
+}
+
+// Shutdown implements the component.Component Shutdown method. It stops accepting
+// new events and waits until pending events have been handled or DrainTimeout
+// has elapsed. It runs after the Shutdown of the components configured after
+// it, and before any Uninit.
+func (c *Component[T]) Shutdown(ctx context.Context) error {
+	if timeout := c.Options().DrainTimeout.Value(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := c.ConcurrentEventSystem.Close(ctx); err != nil {
+		return fmt.Errorf("failed to drain events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package eventsystem_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/component"
+	"github.com/gopherd/core/component/eventsystem"
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/types"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging during tests
+	originalLogger := slog.Default()
+	defer slog.SetDefault(originalLogger)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	m.Run()
+}
+
+type mockContainer struct {
+	components map[string]component.Component
+}
+
+func (c *mockContainer) GetComponent(uuid string) component.Component {
+	return c.components[uuid]
+}
+
+func (c *mockContainer) Logger() *slog.Logger {
+	return slog.Default()
+}
+
+type loginEvent struct {
+	User string
+}
+
+func (e loginEvent) Typeof() string { return "login" }
+
+// dependentComponent records the order of its lifecycle steps and handled events.
+type dependentComponent struct {
+	component.BaseComponentWithRefs[struct{}, struct {
+		Events component.Reference[eventsystem.API[string]]
+	}]
+	stopping chan struct{}
+
+	mu    sync.Mutex
+	steps []string
+}
+
+func (c *dependentComponent) record(step string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = append(c.steps, step)
+}
+
+func (c *dependentComponent) Init(ctx context.Context) error {
+	c.stopping = make(chan struct{})
+	c.Refs().Events.Component().AddListener(event.Listen("login", func(ctx context.Context, e loginEvent) error {
+		// hold queued events until the component is shut down
+		<-c.stopping
+		c.record("event:" + e.User)
+		return nil
+	}))
+	return nil
+}
+
+func (c *dependentComponent) Shutdown(ctx context.Context) error {
+	if err := c.Refs().Events.Component().DispatchEvent(ctx, loginEvent{User: "bye"}); err != nil {
+		return err
+	}
+	c.record("shutdown")
+	close(c.stopping)
+	return nil
+}
+
+func (c *dependentComponent) Uninit(ctx context.Context) error {
+	c.record("uninit")
+	return nil
+}
+
+func setup(t *testing.T, container *mockContainer, c component.Component, config component.Config) {
+	t.Helper()
+	if err := c.Setup(container, &config, false); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	container.components[config.UUID] = c
+}
+
+func TestComponent(t *testing.T) {
+	container := &mockContainer{components: make(map[string]component.Component)}
+	es, err := component.Create(eventsystem.Name)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	setup(t, container, es, component.Config{
+		Name:    eventsystem.Name,
+		UUID:    "events",
+		Options: types.RawObject(`{"Async":true,"Ordered":true,"QueueSize":64,"Backpressure":"block","DrainTimeout":"5s"}`),
+	})
+	dependent := new(dependentComponent)
+	setup(t, container, dependent, component.Config{
+		Name: "dependent",
+		UUID: "dependent",
+		Refs: types.RawObject(`{"Events":"events"}`),
+	})
+
+	group := component.NewGroup()
+	group.AddComponent("events", es)
+	group.AddComponent("dependent", dependent)
+	ctx := context.Background()
+	if err := group.Init(ctx); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := group.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	api := dependent.Refs().Events.Component()
+	const n = 20
+	for i := 0; i < n; i++ {
+		if err := api.DispatchEvent(ctx, loginEvent{User: "alice"}); err != nil {
+			t.Fatalf("DispatchEvent failed: %v", err)
+		}
+	}
+
+	if err := group.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := api.DispatchEvent(ctx, loginEvent{}); err != event.ErrClosed {
+		t.Errorf("Expected ErrClosed after shutdown, got %v", err)
+	}
+	if err := group.Uninit(ctx); err != nil {
+		t.Fatalf("Uninit failed: %v", err)
+	}
+
+	// the dependent is shut down first, then the events, including the one
+	// dispatched during its shutdown, are drained before it is uninitialized
+	want := []string{"shutdown"}
+	for i := 0; i < n; i++ {
+		want = append(want, "event:alice")
+	}
+	want = append(want, "event:bye", "uninit")
+	if !slices.Equal(dependent.steps, want) {
+		t.Errorf("Expected steps %v, got %v", want, dependent.steps)
+	}
+}
+
+func TestComponentDrainTimeout(t *testing.T) {
+	container := &mockContainer{components: make(map[string]component.Component)}
+	es, err := component.Create(eventsystem.Name)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	setup(t, container, es, component.Config{
+		Name:    eventsystem.Name,
+		UUID:    "events",
+		Options: types.RawObject(`{"Async":true,"QueueSize":1,"DrainTimeout":"10ms"}`),
+	})
+	api := es.(eventsystem.API[string])
+	release := make(chan struct{})
+	defer close(release)
+	api.AddListener(event.Listen("login", func(ctx context.Context, e loginEvent) error {
+		<-release
+		return nil
+	}))
+	api.DispatchEvent(context.Background(), loginEvent{})
+	api.DispatchEvent(context.Background(), loginEvent{})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- es.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Shutdown did not return within the drain timeout")
+	}
+}
+
+func TestComponentSync(t *testing.T) {
+	container := &mockContainer{components: make(map[string]component.Component)}
+	es, err := component.Create(eventsystem.Name)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	setup(t, container, es, component.Config{Name: eventsystem.Name, UUID: "events"})
+	api := es.(eventsystem.API[string])
+	var handled bool
+	api.AddListener(event.Listen("login", func(ctx context.Context, e loginEvent) error {
+		handled = true
+		return nil
+	}))
+	if err := api.DispatchEvent(context.Background(), loginEvent{}); err != nil {
+		t.Fatalf("DispatchEvent failed: %v", err)
+	}
+	if !handled {
+		t.Errorf("Expected event to be handled synchronously")
+	}
+	if err := es.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		options string
+		wantErr string
+	}{
+		{"Negative queue size", `{"QueueSize":-1}`, "invalid queue size"},
+		{"Negative workers", `{"Workers":-1}`, "invalid number of workers"},
+		{"Invalid backpressure", `{"Backpressure":"wait"}`, "failed to unmarshal options"},
+		{"Invalid dispatch mode", `{"DispatchMode":"first"}`, "failed to unmarshal options"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(eventsystem.Component[string])
+			config := &component.Config{Name: eventsystem.Name, Options: types.RawObject(tt.options)}
+			err := c.Setup(&mockContainer{}, config, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	eventsystem.Register[int]("test/eventsystem/int")
+	c, err := component.Create("test/eventsystem/int")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, ok := c.(eventsystem.API[int]); !ok {
+		t.Errorf("Expected API[int], got %T", c)
+	}
+}
@@ -1,4 +1,8 @@
 // Package heap provides a generic heap implementation.
+//
+// The functions of this package operate on any type implementing Interface.
+// PriorityQueue, IndexedHeap and MinMaxHeap are ready-to-use heaps ordered by
+// a comparison function.
 package heap
 
 import "sort"
@@ -0,0 +1,159 @@
+package heap
+
+// Item is an element of an IndexedHeap. It is the handle used to update or
+// remove the element after it has been pushed.
+type Item[T any] struct {
+	value T
+	index int // position in the heap, or -1 if the item is not in a heap
+}
+
+// Value returns the value of the item.
+func (it *Item[T]) Value() T {
+	return it.value
+}
+
+// IndexedHeap is a min-heap of elements ordered by a comparison function,
+// which supports changing the priority of an element and removing it by the
+// handle returned when it was pushed, for example to reschedule a timer or
+// to decrease the distance of a node in a shortest path search.
+//
+// The zero value is not usable; create an IndexedHeap with NewIndexedHeap.
+type IndexedHeap[T any] struct {
+	cmp   func(a, b T) int
+	items []*Item[T]
+}
+
+// NewIndexedHeap creates an IndexedHeap ordered by the comparison function cmp,
+// with the same semantics as for NewPriorityQueue.
+func NewIndexedHeap[T any](cmp func(a, b T) int) *IndexedHeap[T] {
+	return &IndexedHeap[T]{cmp: cmp}
+}
+
+// Len returns the number of elements in the heap.
+func (h *IndexedHeap[T]) Len() int {
+	return len(h.items)
+}
+
+// Push adds x to the heap and returns its handle.
+// The complexity is O(log n) where n = h.Len().
+func (h *IndexedHeap[T]) Push(x T) *Item[T] {
+	it := &Item[T]{value: x, index: len(h.items)}
+	h.items = append(h.items, it)
+	h.up(it.index)
+	return it
+}
+
+// Peek returns the handle of the least element without removing it, or nil
+// if the heap is empty.
+func (h *IndexedHeap[T]) Peek() *Item[T] {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.items[0]
+}
+
+// Pop removes the least element and returns its handle, or nil if the heap is
+// empty.
+// The complexity is O(log n) where n = h.Len().
+func (h *IndexedHeap[T]) Pop() *Item[T] {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.remove(0)
+}
+
+// Contains reports whether the item is in the heap.
+func (h *IndexedHeap[T]) Contains(it *Item[T]) bool {
+	return it.index >= 0 && it.index < len(h.items) && h.items[it.index] == it
+}
+
+// Update sets the value of the item to x and re-establishes the heap ordering,
+// which covers decreasing and increasing the priority of the item.
+// It panics if the item is not in the heap.
+// The complexity is O(log n) where n = h.Len().
+func (h *IndexedHeap[T]) Update(it *Item[T], x T) {
+	h.checkItem(it)
+	it.value = x
+	if !h.down(it.index) {
+		h.up(it.index)
+	}
+}
+
+// Remove removes the item from the heap.
+// It panics if the item is not in the heap.
+// The complexity is O(log n) where n = h.Len().
+func (h *IndexedHeap[T]) Remove(it *Item[T]) {
+	h.checkItem(it)
+	h.remove(it.index)
+}
+
+// Clear removes all elements from the heap.
+func (h *IndexedHeap[T]) Clear() {
+	for _, it := range h.items {
+		it.index = -1
+	}
+	clear(h.items)
+	h.items = h.items[:0]
+}
+
+func (h *IndexedHeap[T]) checkItem(it *Item[T]) {
+	if !h.Contains(it) {
+		panic("heap: item not in heap")
+	}
+}
+
+func (h *IndexedHeap[T]) remove(i int) *Item[T] {
+	n := len(h.items) - 1
+	it := h.items[i]
+	if i != n {
+		h.swap(i, n)
+	}
+	h.items[n] = nil
+	h.items = h.items[:n]
+	if i != n && !h.down(i) {
+		h.up(i)
+	}
+	it.index = -1
+	return it
+}
+
+func (h *IndexedHeap[T]) less(i, j int) bool {
+	return h.cmp(h.items[i].value, h.items[j].value) < 0
+}
+
+func (h *IndexedHeap[T]) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *IndexedHeap[T]) up(j int) {
+	for j > 0 {
+		i := (j - 1) / 2 // parent
+		if !h.less(j, i) {
+			break
+		}
+		h.swap(i, j)
+		j = i
+	}
+}
+
+func (h *IndexedHeap[T]) down(i0 int) bool {
+	n := len(h.items)
+	i := i0
+	for {
+		j := 2*i + 1
+		if j >= n || j < 0 { // j < 0 after int overflow
+			break
+		}
+		if j2 := j + 1; j2 < n && h.less(j2, j) {
+			j = j2 // right child
+		}
+		if !h.less(j, i) {
+			break
+		}
+		h.swap(i, j)
+		i = j
+	}
+	return i > i0
+}
@@ -0,0 +1,160 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gopherd/core/container/heap"
+)
+
+type timer struct {
+	name     string
+	deadline int
+}
+
+func compareTimers(a, b timer) int {
+	return cmp.Compare(a.deadline, b.deadline)
+}
+
+func TestIndexedHeap(t *testing.T) {
+	h := heap.NewIndexedHeap(compareTimers)
+	a := h.Push(timer{"a", 30})
+	b := h.Push(timer{"b", 20})
+	c := h.Push(timer{"c", 10})
+
+	// decrease key
+	h.Update(a, timer{"a", 5})
+	if top := h.Peek(); top != a {
+		t.Errorf("expected a on top, got %v", top.Value())
+	}
+	// increase key
+	h.Update(a, timer{"a", 40})
+	if top := h.Peek(); top != c {
+		t.Errorf("expected c on top, got %v", top.Value())
+	}
+
+	h.Remove(c)
+	if h.Contains(c) || !h.Contains(b) || h.Len() != 2 {
+		t.Errorf("expected c to be removed")
+	}
+
+	if it := h.Pop(); it != b || h.Contains(b) {
+		t.Errorf("expected b to be popped, got %v", it.Value())
+	}
+	if it := h.Pop(); it != a {
+		t.Errorf("expected a to be popped, got %v", it.Value())
+	}
+	if h.Pop() != nil || h.Peek() != nil {
+		t.Errorf("expected empty heap")
+	}
+}
+
+func TestIndexedHeapForeignItem(t *testing.T) {
+	h1 := heap.NewIndexedHeap(cmp.Compare[int])
+	h2 := heap.NewIndexedHeap(cmp.Compare[int])
+	it := h1.Push(1)
+	h2.Push(2)
+	if h2.Contains(it) {
+		t.Errorf("expected item not to be in the other heap")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic when updating an item of another heap")
+		}
+	}()
+	h2.Update(it, 0)
+}
+
+func TestIndexedHeapWithRandomData(t *testing.T) {
+	h := heap.NewIndexedHeap(cmp.Compare[int])
+	items := make([]*heap.Item[int], 1000)
+	for i := range items {
+		items[i] = h.Push(rand.Intn(1000))
+	}
+	// update or remove a random half of the items
+	values := make([]int, 0, len(items))
+	for _, it := range items {
+		switch rand.Intn(4) {
+		case 0:
+			h.Remove(it)
+		case 1:
+			h.Update(it, rand.Intn(1000))
+			values = append(values, it.Value())
+		default:
+			values = append(values, it.Value())
+		}
+	}
+	sort.Ints(values)
+	for i, want := range values {
+		if x := h.Pop().Value(); x != want {
+			t.Fatalf("pop %d got %d, want %d", i, x, want)
+		}
+	}
+	if h.Len() != 0 {
+		t.Errorf("expected heap to be empty, got length %d", h.Len())
+	}
+	h.Push(1)
+	h.Clear()
+	if h.Len() != 0 || h.Contains(items[0]) {
+		t.Errorf("expected heap to be cleared")
+	}
+}
+
+// indexedInt is an element of an indexedIntHeap which tracks its index.
+type indexedInt struct {
+	value int
+	index int
+}
+
+// indexedIntHeap is a min-heap of indexedInt elements for heap.Fix.
+type indexedIntHeap []*indexedInt
+
+func (h indexedIntHeap) Len() int           { return len(h) }
+func (h indexedIntHeap) Less(i, j int) bool { return h[i].value < h[j].value }
+func (h indexedIntHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *indexedIntHeap) Push(x *indexedInt) {
+	x.index = len(*h)
+	*h = append(*h, x)
+}
+func (h *indexedIntHeap) Pop() *indexedInt {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+func BenchmarkIndexedHeapUpdate(b *testing.B) {
+	const n = 10000
+	b.Run("Interface", func(b *testing.B) {
+		h := &indexedIntHeap{}
+		items := make([]*indexedInt, n)
+		for i := range items {
+			items[i] = &indexedInt{value: rand.Int()}
+			heap.Push(h, items[i])
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			it := items[i%n]
+			it.value = rand.Int()
+			heap.Fix(h, it.index)
+		}
+	})
+	b.Run("IndexedHeap", func(b *testing.B) {
+		h := heap.NewIndexedHeap(cmp.Compare[int])
+		items := make([]*heap.Item[int], n)
+		for i := range items {
+			items[i] = h.Push(rand.Int())
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			h.Update(items[i%n], rand.Int())
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package heap
+
+import "math/bits"
+
+// MinMaxHeap is a double-ended priority queue of elements ordered by a
+// comparison function, which gives access to both the least and the greatest
+// element in constant time and removes either in logarithmic time.
+//
+// Elements on even levels of the tree are less than or equal to their
+// descendants, and elements on odd levels are greater than or equal to them.
+//
+// The zero value is not usable; create a MinMaxHeap with NewMinMaxHeap.
+type MinMaxHeap[T any] struct {
+	cmp  func(a, b T) int
+	data []T
+}
+
+// NewMinMaxHeap creates a MinMaxHeap ordered by the comparison function cmp,
+// with the same semantics as for NewPriorityQueue.
+func NewMinMaxHeap[T any](cmp func(a, b T) int) *MinMaxHeap[T] {
+	return &MinMaxHeap[T]{cmp: cmp}
+}
+
+// Len returns the number of elements in the heap.
+func (h *MinMaxHeap[T]) Len() int {
+	return len(h.data)
+}
+
+// Push adds x to the heap.
+// The complexity is O(log n) where n = h.Len().
+func (h *MinMaxHeap[T]) Push(x T) {
+	h.data = append(h.data, x)
+	h.up(len(h.data) - 1)
+}
+
+// Min returns the least element without removing it. It returns false if the
+// heap is empty.
+func (h *MinMaxHeap[T]) Min() (x T, ok bool) {
+	if len(h.data) == 0 {
+		return
+	}
+	return h.data[0], true
+}
+
+// Max returns the greatest element without removing it. It returns false if
+// the heap is empty.
+func (h *MinMaxHeap[T]) Max() (x T, ok bool) {
+	if len(h.data) == 0 {
+		return
+	}
+	return h.data[h.maxIndex()], true
+}
+
+// PopMin removes and returns the least element. It returns false if the heap
+// is empty.
+// The complexity is O(log n) where n = h.Len().
+func (h *MinMaxHeap[T]) PopMin() (x T, ok bool) {
+	if len(h.data) == 0 {
+		return
+	}
+	return h.remove(0), true
+}
+
+// PopMax removes and returns the greatest element. It returns false if the
+// heap is empty.
+// The complexity is O(log n) where n = h.Len().
+func (h *MinMaxHeap[T]) PopMax() (x T, ok bool) {
+	if len(h.data) == 0 {
+		return
+	}
+	return h.remove(h.maxIndex()), true
+}
+
+// Clear removes all elements from the heap, keeping the allocated memory.
+func (h *MinMaxHeap[T]) Clear() {
+	clear(h.data)
+	h.data = h.data[:0]
+}
+
+// maxIndex returns the index of the greatest element of a non-empty heap,
+// which is one of the children of the root unless the root is alone.
+func (h *MinMaxHeap[T]) maxIndex() int {
+	switch len(h.data) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if h.cmp(h.data[2], h.data[1]) > 0 {
+		return 2
+	}
+	return 1
+}
+
+func (h *MinMaxHeap[T]) remove(i int) T {
+	n := len(h.data) - 1
+	x := h.data[i]
+	h.data[i] = h.data[n]
+	var zero T
+	h.data[n] = zero
+	h.data = h.data[:n]
+	if i < n {
+		h.down(i)
+	}
+	return x
+}
+
+// isMinLevel reports whether the element at index i is on an even level.
+func isMinLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+
+// before reports whether the element at index i belongs above the element at
+// index j on a min level if minLevel is true, or on a max level otherwise.
+func (h *MinMaxHeap[T]) before(i, j int, minLevel bool) bool {
+	c := h.cmp(h.data[i], h.data[j])
+	if minLevel {
+		return c < 0
+	}
+	return c > 0
+}
+
+func (h *MinMaxHeap[T]) swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+}
+
+func (h *MinMaxHeap[T]) up(i int) {
+	if i == 0 {
+		return
+	}
+	minLevel := isMinLevel(i)
+	p := (i - 1) / 2
+	if h.before(p, i, minLevel) {
+		// the element belongs to the levels of the other kind
+		h.swap(i, p)
+		i, minLevel = p, !minLevel
+	}
+	// move up through the grandparents on the levels of the same kind
+	for i > 2 {
+		g := ((i-1)/2 - 1) / 2
+		if !h.before(i, g, minLevel) {
+			break
+		}
+		h.swap(i, g)
+		i = g
+	}
+}
+
+func (h *MinMaxHeap[T]) down(i int) {
+	n := len(h.data)
+	minLevel := isMinLevel(i)
+	for {
+		// find the first among the children and grandchildren
+		c := 2*i + 1
+		if c >= n {
+			return
+		}
+		m := c
+		for _, j := range [...]int{c + 1, 2*c + 1, 2*c + 2, 2*c + 3, 2*c + 4} {
+			if j < n && h.before(j, m, minLevel) {
+				m = j
+			}
+		}
+		if !h.before(m, i, minLevel) {
+			return
+		}
+		h.swap(m, i)
+		if m <= c+1 {
+			// the descendants of the child are not before it, so they are
+			// not before the moved element either
+			return
+		}
+		if p := (m - 1) / 2; h.before(p, m, minLevel) {
+			h.swap(m, p)
+		}
+		i = m
+	}
+}
@@ -0,0 +1,148 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gopherd/core/container/heap"
+)
+
+func TestMinMaxHeap(t *testing.T) {
+	h := heap.NewMinMaxHeap(cmp.Compare[int])
+	if _, ok := h.Min(); ok {
+		t.Errorf("expected min of empty heap to fail")
+	}
+	if _, ok := h.PopMax(); ok {
+		t.Errorf("expected pop max of empty heap to fail")
+	}
+	for _, x := range []int{5, 1, 9, 3, 7} {
+		h.Push(x)
+	}
+	if x, _ := h.Min(); x != 1 {
+		t.Errorf("expected min 1, got %d", x)
+	}
+	if x, _ := h.Max(); x != 9 {
+		t.Errorf("expected max 9, got %d", x)
+	}
+	if x, _ := h.PopMax(); x != 9 {
+		t.Errorf("pop max got %d, want 9", x)
+	}
+	if x, _ := h.PopMin(); x != 1 {
+		t.Errorf("pop min got %d, want 1", x)
+	}
+	if x, _ := h.PopMax(); x != 7 {
+		t.Errorf("pop max got %d, want 7", x)
+	}
+	if h.Len() != 2 {
+		t.Errorf("expected length 2, got %d", h.Len())
+	}
+	h.Clear()
+	if h.Len() != 0 {
+		t.Errorf("expected length 0, got %d", h.Len())
+	}
+}
+
+func TestMinMaxHeapWithRandomData(t *testing.T) {
+	for n := 1; n <= 100; n++ {
+		h := heap.NewMinMaxHeap(cmp.Compare[int])
+		data := make([]int, n)
+		for i := range data {
+			data[i] = rand.Intn(50)
+			h.Push(data[i])
+		}
+		sort.Ints(data)
+		// pop from both ends at random
+		lo, hi := 0, n-1
+		for lo <= hi {
+			if rand.Intn(2) == 0 {
+				if x, _ := h.PopMin(); x != data[lo] {
+					t.Fatalf("n=%d: pop min got %d, want %d", n, x, data[lo])
+				}
+				lo++
+			} else {
+				if x, _ := h.PopMax(); x != data[hi] {
+					t.Fatalf("n=%d: pop max got %d, want %d", n, x, data[hi])
+				}
+				hi--
+			}
+		}
+		if h.Len() != 0 {
+			t.Fatalf("n=%d: expected heap to be empty, got length %d", n, h.Len())
+		}
+	}
+}
+
+// dualInt is an element of both heaps of a pair of dualIntHeaps, which tracks
+// its index in each of them.
+type dualInt struct {
+	value int
+	index [2]int
+}
+
+// dualIntHeap is a min-heap if side is 0 and a max-heap if side is 1.
+type dualIntHeap struct {
+	side  int
+	items []*dualInt
+}
+
+func (h *dualIntHeap) Len() int { return len(h.items) }
+func (h *dualIntHeap) Less(i, j int) bool {
+	if h.side == 0 {
+		return h.items[i].value < h.items[j].value
+	}
+	return h.items[i].value > h.items[j].value
+}
+func (h *dualIntHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index[h.side] = i
+	h.items[j].index[h.side] = j
+}
+func (h *dualIntHeap) Push(x *dualInt) {
+	x.index[h.side] = len(h.items)
+	h.items = append(h.items, x)
+}
+func (h *dualIntHeap) Pop() *dualInt {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
+
+func BenchmarkMinMaxHeap(b *testing.B) {
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = rand.Int()
+	}
+	b.Run("Interface", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			lo, hi := &dualIntHeap{side: 0}, &dualIntHeap{side: 1}
+			for _, x := range data {
+				it := &dualInt{value: x}
+				heap.Push(lo, it)
+				heap.Push(hi, it)
+			}
+			for lo.Len() > 0 {
+				it := heap.Pop(lo)
+				heap.Remove(hi, it.index[1])
+				if hi.Len() > 0 {
+					it = heap.Pop(hi)
+					heap.Remove(lo, it.index[0])
+				}
+			}
+		}
+	})
+	b.Run("MinMaxHeap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			h := heap.NewMinMaxHeap(cmp.Compare[int])
+			for _, x := range data {
+				h.Push(x)
+			}
+			for h.Len() > 0 {
+				h.PopMin()
+				h.PopMax()
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package heap
+
+// PriorityQueue is a min-heap of elements ordered by a comparison function.
+// The element popped first is the least one. Unlike the functions of this
+// package, it needs no Interface implementation and avoids the overhead of
+// calling methods through an interface.
+//
+// The zero value is not usable; create a PriorityQueue with NewPriorityQueue.
+type PriorityQueue[T any] struct {
+	cmp  func(a, b T) int
+	data []T
+}
+
+// NewPriorityQueue creates a PriorityQueue ordered by the comparison function
+// cmp, which returns a negative number if a is less than b, a positive number
+// if a is greater than b and zero otherwise, like cmp.Compare. The queue is
+// initialized with the given elements, which it takes ownership of.
+// The complexity is O(n) where n = len(elements).
+func NewPriorityQueue[T any](cmp func(a, b T) int, elements ...T) *PriorityQueue[T] {
+	q := &PriorityQueue[T]{cmp: cmp, data: elements}
+	for i := len(q.data)/2 - 1; i >= 0; i-- {
+		q.down(i)
+	}
+	return q
+}
+
+// Len returns the number of elements in the queue.
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.data)
+}
+
+// Push adds x to the queue.
+// The complexity is O(log n) where n = q.Len().
+func (q *PriorityQueue[T]) Push(x T) {
+	q.data = append(q.data, x)
+	q.up(len(q.data) - 1)
+}
+
+// Peek returns the least element without removing it. It returns false if the
+// queue is empty.
+func (q *PriorityQueue[T]) Peek() (x T, ok bool) {
+	if len(q.data) == 0 {
+		return
+	}
+	return q.data[0], true
+}
+
+// Pop removes and returns the least element. It returns false if the queue is
+// empty.
+// The complexity is O(log n) where n = q.Len().
+func (q *PriorityQueue[T]) Pop() (x T, ok bool) {
+	n := len(q.data) - 1
+	if n < 0 {
+		return
+	}
+	x = q.data[0]
+	q.data[0] = q.data[n]
+	var zero T
+	q.data[n] = zero
+	q.data = q.data[:n]
+	if n > 0 {
+		q.down(0)
+	}
+	return x, true
+}
+
+// Clear removes all elements from the queue, keeping the allocated memory.
+func (q *PriorityQueue[T]) Clear() {
+	clear(q.data)
+	q.data = q.data[:0]
+}
+
+func (q *PriorityQueue[T]) up(j int) {
+	x := q.data[j]
+	for j > 0 {
+		i := (j - 1) / 2 // parent
+		if q.cmp(x, q.data[i]) >= 0 {
+			break
+		}
+		q.data[j] = q.data[i]
+		j = i
+	}
+	q.data[j] = x
+}
+
+func (q *PriorityQueue[T]) down(i int) {
+	n := len(q.data)
+	x := q.data[i]
+	for {
+		j := 2*i + 1
+		if j >= n || j < 0 { // j < 0 after int overflow
+			break
+		}
+		if j2 := j + 1; j2 < n && q.cmp(q.data[j2], q.data[j]) < 0 {
+			j = j2 // right child
+		}
+		if q.cmp(q.data[j], x) >= 0 {
+			break
+		}
+		q.data[i] = q.data[j]
+		i = j
+	}
+	q.data[i] = x
+}
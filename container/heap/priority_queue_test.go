@@ -0,0 +1,89 @@
+package heap_test
+
+import (
+	"cmp"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gopherd/core/container/heap"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := heap.NewPriorityQueue(cmp.Compare[int], 5, 3, 8, 1)
+	q.Push(4)
+	q.Push(0)
+	if x, ok := q.Peek(); !ok || x != 0 {
+		t.Errorf("expected peek 0, got %d, %v", x, ok)
+	}
+	for _, want := range []int{0, 1, 3, 4, 5, 8} {
+		if x, ok := q.Pop(); !ok || x != want {
+			t.Errorf("pop got %d, %v, want %d", x, ok, want)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Errorf("expected pop of empty queue to fail")
+	}
+	if _, ok := q.Peek(); ok {
+		t.Errorf("expected peek of empty queue to fail")
+	}
+}
+
+func TestPriorityQueueWithRandomData(t *testing.T) {
+	q := heap.NewPriorityQueue(func(a, b int) int { return b - a }) // max-heap
+	data := make([]int, 1000)
+	for i := range data {
+		data[i] = rand.Intn(100)
+		q.Push(data[i])
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(data)))
+	for i, want := range data {
+		if x, _ := q.Pop(); x != want {
+			t.Fatalf("pop %d got %d, want %d", i, x, want)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected queue to be empty, got length %d", q.Len())
+	}
+}
+
+func TestPriorityQueueClear(t *testing.T) {
+	q := heap.NewPriorityQueue(cmp.Compare[string], "b", "a")
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("expected length 0, got %d", q.Len())
+	}
+	q.Push("c")
+	if x, _ := q.Pop(); x != "c" {
+		t.Errorf("pop got %q, want %q", x, "c")
+	}
+}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	data := make([]int, 10000)
+	for i := range data {
+		data[i] = rand.Int()
+	}
+	b.Run("Interface", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			h := &intHeap{}
+			for _, x := range data {
+				heap.Push(h, x)
+			}
+			for h.Len() > 0 {
+				heap.Pop(h)
+			}
+		}
+	})
+	b.Run("PriorityQueue", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			q := heap.NewPriorityQueue(cmp.Compare[int])
+			for _, x := range data {
+				q.Push(x)
+			}
+			for q.Len() > 0 {
+				q.Pop()
+			}
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package history
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ChangeKind represents the kind of a change.
+type ChangeKind int
+
+const (
+	ChangeSet     ChangeKind = iota // Key is set from Old to New
+	ChangeRemove                    // Key with value Old is removed
+	ChangeInsert                    // Key with value New is inserted
+	ChangeClear                     // All elements are removed
+	ChangeReverse                   // The order of elements is reversed
+)
+
+var changeKindNames = [...]string{
+	ChangeSet:     "set",
+	ChangeRemove:  "remove",
+	ChangeInsert:  "insert",
+	ChangeClear:   "clear",
+	ChangeReverse: "reverse",
+}
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	if k >= 0 && int(k) < len(changeKindNames) {
+		return changeKindNames[k]
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(changeKindNames) {
+		return nil, fmt.Errorf("history: invalid change kind %d", int(k))
+	}
+	return []byte(changeKindNames[k]), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *ChangeKind) UnmarshalText(text []byte) error {
+	for i, name := range changeKindNames {
+		if name == string(text) {
+			*k = ChangeKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("history: unknown change kind %q", text)
+}
+
+// Change is a record of a mutation of a container, including mutations made
+// by undoing and redoing actions.
+//
+// For a Map, Key is the key and Old and New are values. Setting a new key is
+// a ChangeSet with Replaced set to false.
+// For a Set, Key is the element, and Old and New report the membership of the
+// element before and after the change.
+// For a Slice, Key is the index of the element. Appending is reported as
+// inserting at the end.
+type Change[K, V any] struct {
+	Kind     ChangeKind
+	Key      K    `json:",omitempty"`
+	Old      V    `json:",omitempty"`
+	New      V    `json:",omitempty"`
+	Replaced bool `json:",omitempty"`
+}
+
+// Observable is implemented by containers which report their changes.
+type Observable[K, V any] interface {
+	// Subscribe registers fn to be called after each change and returns a
+	// function which unregisters it.
+	Subscribe(fn func(Change[K, V])) (unsubscribe func())
+}
+
+// observers is a list of change subscribers.
+type observers[C any] struct {
+	nextID int
+	list   []observer[C]
+}
+
+type observer[C any] struct {
+	id int
+	fn func(C)
+}
+
+func (o *observers[C]) subscribe(fn func(C)) func() {
+	o.nextID++
+	id := o.nextID
+	o.list = append(o.list, observer[C]{id: id, fn: fn})
+	return func() {
+		// copy on write so that unsubscribing while notifying is safe
+		o.list = slices.DeleteFunc(slices.Clone(o.list), func(x observer[C]) bool {
+			return x.id == id
+		})
+	}
+}
+
+func (o *observers[C]) notify(c C) {
+	for _, x := range o.list {
+		x.fn(c)
+	}
+}
+
+// Patch is a serializable list of changes which can be applied to a container.
+type Patch[K, V any] []Change[K, V]
+
+// Checkpoint is a position in a ChangeLog.
+type Checkpoint uint64
+
+// ChangeLog records the changes of an Observable container so that the
+// changes since a checkpoint can be extracted as a Patch, e.g. to synchronize
+// the state of the container with remote replicas.
+type ChangeLog[K, V any] struct {
+	base        Checkpoint
+	changes     []Change[K, V]
+	unsubscribe func()
+}
+
+// NewChangeLog creates a new ChangeLog which records the changes of src.
+func NewChangeLog[K, V any](src Observable[K, V]) *ChangeLog[K, V] {
+	l := &ChangeLog[K, V]{}
+	l.unsubscribe = src.Subscribe(func(c Change[K, V]) {
+		l.changes = append(l.changes, c)
+	})
+	return l
+}
+
+// Close stops recording changes.
+func (l *ChangeLog[K, V]) Close() {
+	if l.unsubscribe != nil {
+		l.unsubscribe()
+		l.unsubscribe = nil
+	}
+}
+
+// Checkpoint returns the current position of the log.
+func (l *ChangeLog[K, V]) Checkpoint() Checkpoint {
+	return l.base + Checkpoint(len(l.changes))
+}
+
+// Len returns the number of changes held by the log.
+func (l *ChangeLog[K, V]) Len() int {
+	return len(l.changes)
+}
+
+// Since returns a copy of the changes recorded since the checkpoint.
+// It panics if the changes have been discarded or the checkpoint is ahead of
+// the log.
+func (l *ChangeLog[K, V]) Since(cp Checkpoint) Patch[K, V] {
+	return slices.Clone(l.changes[l.index(cp):])
+}
+
+// Discard drops the changes recorded before the checkpoint.
+func (l *ChangeLog[K, V]) Discard(cp Checkpoint) {
+	i := l.index(cp)
+	n := copy(l.changes, l.changes[i:])
+	clear(l.changes[n:])
+	l.changes = l.changes[:n]
+	l.base = cp
+}
+
+// Flush returns the changes held by the log and discards them.
+func (l *ChangeLog[K, V]) Flush() Patch[K, V] {
+	patch := Patch[K, V](l.changes)
+	l.base += Checkpoint(len(l.changes))
+	l.changes = nil
+	return patch
+}
+
+func (l *ChangeLog[K, V]) index(cp Checkpoint) int {
+	if cp < l.base || cp > l.Checkpoint() {
+		panic(fmt.Sprintf("history: checkpoint %d out of range [%d, %d]", cp, l.base, l.Checkpoint()))
+	}
+	return int(cp - l.base)
+}
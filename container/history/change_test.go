@@ -0,0 +1,276 @@
+package history_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func mapData[K comparable, V any](m *history.Map[K, V]) map[K]V {
+	data := make(map[K]V)
+	m.Range(func(k K, v V) bool {
+		data[k] = v
+		return true
+	})
+	return data
+}
+
+func TestMap_Subscribe(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+	var changes []history.Change[string, int]
+	unsubscribe := m.Subscribe(func(c history.Change[string, int]) {
+		changes = append(changes, c)
+	})
+
+	m.Set("a", 1)
+	m.Set("a", 2)
+	m.Remove("a")
+	m.Remove("missing")
+	s.Undo()
+	s.Redo()
+	unsubscribe()
+	m.Set("b", 3)
+
+	want := []history.Change[string, int]{
+		{Kind: history.ChangeSet, Key: "a", New: 1},
+		{Kind: history.ChangeSet, Key: "a", Old: 1, New: 2, Replaced: true},
+		{Kind: history.ChangeRemove, Key: "a", Old: 2},
+		{Kind: history.ChangeSet, Key: "a", New: 2},
+		{Kind: history.ChangeRemove, Key: "a", Old: 2},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Expected changes %v, got %v", want, changes)
+	}
+}
+
+func TestSet_Subscribe(t *testing.T) {
+	s := history.NewUndoStack()
+	set := history.NewSet[int](s, 0)
+	var changes []history.Change[int, bool]
+	set.Subscribe(func(c history.Change[int, bool]) {
+		changes = append(changes, c)
+	})
+
+	set.Add(1)
+	set.Add(1)
+	set.Remove(1)
+	set.Remove(1)
+	set.Clear()
+	s.Undo()
+	s.Undo()
+
+	want := []history.Change[int, bool]{
+		{Kind: history.ChangeInsert, Key: 1, New: true},
+		{Kind: history.ChangeRemove, Key: 1, Old: true},
+		{Kind: history.ChangeClear},
+		{Kind: history.ChangeInsert, Key: 1, New: true},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Expected changes %v, got %v", want, changes)
+	}
+}
+
+func TestSlice_Subscribe(t *testing.T) {
+	s := history.NewUndoStack()
+	sl := history.NewSlice[string](s, 0, 0)
+	var changes []history.Change[int, string]
+	sl.Subscribe(func(c history.Change[int, string]) {
+		changes = append(changes, c)
+	})
+
+	sl.Append("a", "b")
+	sl.Insert(1, "x")
+	sl.Set(0, "y")
+	sl.RemoveAt(2)
+	sl.Reverse()
+	changes = changes[:0]
+	s.Undo() // reverse
+	s.Undo() // remove
+	s.Undo() // set
+	s.Undo() // insert
+
+	want := []history.Change[int, string]{
+		{Kind: history.ChangeReverse},
+		{Kind: history.ChangeInsert, Key: 2, New: "b"},
+		{Kind: history.ChangeSet, Key: 0, Old: "y", New: "a", Replaced: true},
+		{Kind: history.ChangeRemove, Key: 1, Old: "x"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Expected changes %v, got %v", want, changes)
+	}
+}
+
+func TestChangeLog(t *testing.T) {
+	m := history.NewMap[string, int](history.NewUndoStack(), 0)
+	log := history.NewChangeLog[string, int](m)
+	defer log.Close()
+
+	m.Set("a", 1)
+	cp := log.Checkpoint()
+	m.Set("b", 2)
+	m.Remove("a")
+
+	if patch := log.Since(cp); len(patch) != 2 || patch[0].Key != "b" || patch[1].Kind != history.ChangeRemove {
+		t.Errorf("Unexpected patch since checkpoint: %v", patch)
+	}
+	if patch := log.Since(0); len(patch) != 3 {
+		t.Errorf("Expected 3 changes since the beginning, got %v", patch)
+	}
+
+	log.Discard(cp)
+	if log.Len() != 2 || log.Checkpoint() != cp+2 {
+		t.Errorf("Expected 2 changes after discarding, got %d at %d", log.Len(), log.Checkpoint())
+	}
+	if patch := log.Since(cp); len(patch) != 2 {
+		t.Errorf("Expected checkpoint to remain valid, got %v", patch)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for a discarded checkpoint")
+			}
+		}()
+		log.Since(0)
+	}()
+
+	if patch := log.Flush(); len(patch) != 2 || log.Len() != 0 || log.Checkpoint() != cp+2 {
+		t.Errorf("Unexpected flush result: %v, %d at %d", patch, log.Len(), log.Checkpoint())
+	}
+	log.Close()
+	m.Set("c", 3)
+	if log.Len() != 0 {
+		t.Errorf("Expected closed log not to record changes")
+	}
+}
+
+func TestPatch_Map(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+	replica := m.Clone(&history.BaseRecorder{})
+	log := history.NewChangeLog[string, int](m)
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 3)
+	m.Remove("b")
+	m.Clear()
+	m.Set("c", 4)
+	s.Undo()
+	s.Undo()
+	s.Redo()
+	s.Undo()
+
+	data, err := json.Marshal(log.Flush())
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var patch history.Patch[string, int]
+	if err := json.Unmarshal(data, &patch); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := replica.Apply(patch); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got, want := mapData(replica), mapData(m); !maps.Equal(got, want) {
+		t.Errorf("Expected replica %v, got %v", want, got)
+	}
+
+	if err := replica.Apply(history.Patch[string, int]{{Kind: history.ChangeReverse}}); err == nil {
+		t.Errorf("Expected error for a reverse change")
+	}
+}
+
+func TestPatch_Set(t *testing.T) {
+	s := history.NewUndoStack()
+	set := history.NewSet[int](s, 0)
+	replica := history.NewSet[int](&history.BaseRecorder{}, 0)
+	log := history.NewChangeLog[int, bool](set)
+
+	set.Add(1)
+	set.Add(2)
+	set.Remove(1)
+	set.Clear()
+	set.Add(3)
+	s.Undo()
+	s.Undo()
+
+	if err := replica.Apply(log.Flush()); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if replica.String() != set.String() || !replica.Contains(2) {
+		t.Errorf("Expected replica %v, got %v", set, replica)
+	}
+}
+
+func TestPatch_Slice(t *testing.T) {
+	s := history.NewUndoStack()
+	sl := history.NewSlice[int](s, 0, 0)
+	replica := history.NewSlice[int](&history.BaseRecorder{}, 0, 0)
+	log := history.NewChangeLog[int, int](sl)
+
+	sl.Append(1, 2, 3)
+	sl.Insert(1, 10, 11)
+	sl.Set(0, 5)
+	sl.RemoveAt(4)
+	sl.Reverse()
+	sl.Clear()
+	sl.Append(7)
+	var states []string
+	for s.CanUndo() {
+		states = append(states, sl.String())
+		s.Undo()
+		// each undo step must be replayable on the replica
+		if err := replica.Apply(log.Flush()); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if replica.String() != sl.String() {
+			t.Fatalf("Expected replica %v, got %v", sl, replica)
+		}
+	}
+	for s.Redo() {
+	}
+	if err := replica.Apply(log.Flush()); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if replica.String() != sl.String() || sl.String() != states[0] {
+		t.Errorf("Expected replica %v, got %v", sl, replica)
+	}
+
+	for _, c := range []history.Change[int, int]{
+		{Kind: history.ChangeSet, Key: 5},
+		{Kind: history.ChangeRemove, Key: -1},
+		{Kind: history.ChangeInsert, Key: 3},
+		{Kind: history.ChangeKind(9)},
+	} {
+		t.Run(fmt.Sprint(c), func(t *testing.T) {
+			if err := replica.Apply(history.Patch[int, int]{c}); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestChangeKind_Text(t *testing.T) {
+	data, err := json.Marshal(history.Change[string, int]{Kind: history.ChangeRemove, Key: "a", Old: 1})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"Kind":"remove","Key":"a","Old":1}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+	var k history.ChangeKind
+	if err := k.UnmarshalText([]byte("move")); err == nil {
+		t.Errorf("Expected error for unknown kind")
+	}
+	if _, err := history.ChangeKind(9).MarshalText(); err == nil {
+		t.Errorf("Expected error for invalid kind")
+	}
+	if history.ChangeKind(9).String() != "ChangeKind(9)" {
+		t.Errorf("Unexpected invalid kind representation")
+	}
+}
@@ -0,0 +1,300 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Deque is a generic double-ended queue backed by a ring buffer that supports
+// undo operations.
+type Deque[T any] struct {
+	recorder Recorder
+	data     []T
+	head     int
+	n        int
+}
+
+// NewDeque creates a new Deque with the given recorder and initial capacity.
+func NewDeque[T any](recorder Recorder, cap int) *Deque[T] {
+	return &Deque[T]{
+		recorder: recorder,
+		data:     make([]T, cap),
+	}
+}
+
+// String returns a string representation of the Deque from front to back.
+func (d Deque[T]) String() string {
+	var sb strings.Builder
+	sb.Grow(d.n*9 + 1)
+	sb.WriteByte('[')
+	for i := 0; i < d.n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprint(&sb, d.data[d.index(i)])
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// Clone creates a deep copy of the Deque with a new recorder.
+func (d *Deque[T]) Clone(recorder Recorder) *Deque[T] {
+	return &Deque[T]{
+		recorder: recorder,
+		data:     d.elements(),
+		n:        d.n,
+	}
+}
+
+// Len returns the number of elements in the Deque.
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+// index returns the position in data of the i-th element.
+func (d *Deque[T]) index(i int) int {
+	i += d.head
+	if i >= len(d.data) {
+		i -= len(d.data)
+	}
+	return i
+}
+
+// elements returns a copy of the elements from front to back.
+func (d *Deque[T]) elements() []T {
+	elements := make([]T, d.n)
+	n := copy(elements, d.data[d.head:min(d.head+d.n, len(d.data))])
+	copy(elements[n:], d.data)
+	return elements
+}
+
+// grow ensures there is space for one more element.
+func (d *Deque[T]) grow() {
+	if d.n < len(d.data) {
+		return
+	}
+	data := d.elements()
+	d.data = append(data, make([]T, max(len(data), 4))...)
+	d.head = 0
+}
+
+// At returns the i-th element from the front. It panics if i is out of range.
+func (d *Deque[T]) At(i int) T {
+	d.checkIndex(i)
+	return d.data[d.index(i)]
+}
+
+// Set sets the i-th element from the front to x. It panics if i is out of range.
+func (d *Deque[T]) Set(i int, x T) {
+	d.checkIndex(i)
+	j := d.index(i)
+	d.recorder.PushAction(&dequeUndoSetAction[T]{d: d, i: i, oldValue: d.data[j]})
+	d.data[j] = x
+}
+
+func (d *Deque[T]) checkIndex(i int) {
+	if i < 0 || i >= d.n {
+		panic(fmt.Sprintf("history: deque index %d out of range [0, %d)", i, d.n))
+	}
+}
+
+type dequeUndoSetAction[T any] struct {
+	d        *Deque[T]
+	i        int
+	oldValue T
+	newValue T
+}
+
+func (r *dequeUndoSetAction[T]) Undo() {
+	j := r.d.index(r.i)
+	r.newValue = r.d.data[j]
+	r.d.data[j] = r.oldValue
+}
+
+func (r *dequeUndoSetAction[T]) Redo() {
+	r.d.data[r.d.index(r.i)] = r.newValue
+}
+
+func (r *dequeUndoSetAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
+// Front returns the first element. It returns false if the Deque is empty.
+func (d *Deque[T]) Front() (x T, ok bool) {
+	if d.n == 0 {
+		return
+	}
+	return d.data[d.head], true
+}
+
+// Back returns the last element. It returns false if the Deque is empty.
+func (d *Deque[T]) Back() (x T, ok bool) {
+	if d.n == 0 {
+		return
+	}
+	return d.data[d.index(d.n-1)], true
+}
+
+func (d *Deque[T]) pushBack(x T) {
+	d.grow()
+	d.data[d.index(d.n)] = x
+	d.n++
+}
+
+func (d *Deque[T]) pushFront(x T) {
+	d.grow()
+	d.head--
+	if d.head < 0 {
+		d.head += len(d.data)
+	}
+	d.data[d.head] = x
+	d.n++
+}
+
+func (d *Deque[T]) popBack() T {
+	var zero T
+	j := d.index(d.n - 1)
+	x := d.data[j]
+	d.data[j] = zero
+	d.n--
+	return x
+}
+
+func (d *Deque[T]) popFront() T {
+	var zero T
+	x := d.data[d.head]
+	d.data[d.head] = zero
+	d.head = d.index(1)
+	d.n--
+	return x
+}
+
+// PushBack adds x to the back of the Deque.
+func (d *Deque[T]) PushBack(x T) {
+	d.recorder.PushAction(&dequeUndoPushAction[T]{d: d, x: x, back: true})
+	d.pushBack(x)
+}
+
+// PushFront adds x to the front of the Deque.
+func (d *Deque[T]) PushFront(x T) {
+	d.recorder.PushAction(&dequeUndoPushAction[T]{d: d, x: x})
+	d.pushFront(x)
+}
+
+type dequeUndoPushAction[T any] struct {
+	d    *Deque[T]
+	x    T
+	back bool
+}
+
+func (r *dequeUndoPushAction[T]) Undo() {
+	if r.back {
+		r.d.popBack()
+	} else {
+		r.d.popFront()
+	}
+}
+
+func (r *dequeUndoPushAction[T]) Redo() {
+	if r.back {
+		r.d.pushBack(r.x)
+	} else {
+		r.d.pushFront(r.x)
+	}
+}
+
+func (r *dequeUndoPushAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
+// PopBack removes and returns the last element. It returns false if the Deque is empty.
+func (d *Deque[T]) PopBack() (x T, ok bool) {
+	if d.n == 0 {
+		return
+	}
+	x = d.popBack()
+	d.recorder.PushAction(&dequeUndoPopAction[T]{d: d, x: x, back: true})
+	return x, true
+}
+
+// PopFront removes and returns the first element. It returns false if the Deque is empty.
+func (d *Deque[T]) PopFront() (x T, ok bool) {
+	if d.n == 0 {
+		return
+	}
+	x = d.popFront()
+	d.recorder.PushAction(&dequeUndoPopAction[T]{d: d, x: x})
+	return x, true
+}
+
+type dequeUndoPopAction[T any] struct {
+	d    *Deque[T]
+	x    T
+	back bool
+}
+
+func (r *dequeUndoPopAction[T]) Undo() {
+	if r.back {
+		r.d.pushBack(r.x)
+	} else {
+		r.d.pushFront(r.x)
+	}
+}
+
+func (r *dequeUndoPopAction[T]) Redo() {
+	if r.back {
+		r.d.popBack()
+	} else {
+		r.d.popFront()
+	}
+}
+
+func (r *dequeUndoPopAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
+// Range calls f sequentially for each element from front to back.
+// If f returns false, Range stops the iteration.
+func (d *Deque[T]) Range(f func(T) bool) bool {
+	for i := 0; i < d.n; i++ {
+		if !f(d.data[d.index(i)]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all elements from the Deque. The ring buffer is handed over to
+// the recorded action instead of being copied, and the Deque starts again
+// without one.
+func (d *Deque[T]) Clear() {
+	d.recorder.PushAction(&dequeUndoClearAction[T]{d: d, data: d.data, head: d.head, n: d.n})
+	d.clear()
+}
+
+func (d *Deque[T]) clear() {
+	d.data = nil
+	d.head = 0
+	d.n = 0
+}
+
+type dequeUndoClearAction[T any] struct {
+	d    *Deque[T]
+	data []T
+	head int
+	n    int
+}
+
+func (r *dequeUndoClearAction[T]) Undo() {
+	r.d.data, r.d.head, r.d.n = r.data, r.head, r.n
+}
+
+func (r *dequeUndoClearAction[T]) Redo() {
+	r.data, r.head, r.n = r.d.data, r.d.head, r.d.n
+	r.d.clear()
+}
+
+func (r *dequeUndoClearAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + len(r.data)*sizeOf[T]()
+}
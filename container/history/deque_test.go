@@ -0,0 +1,105 @@
+package history_test
+
+import (
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestDeque_PushPop(t *testing.T) {
+	d := history.NewDeque[int](&history.BaseRecorder{}, 0)
+	if _, ok := d.PopFront(); ok {
+		t.Errorf("Expected PopFront on empty deque to fail")
+	}
+	if _, ok := d.Back(); ok {
+		t.Errorf("Expected Back on empty deque to fail")
+	}
+	for i := 1; i <= 5; i++ {
+		d.PushBack(i)
+		d.PushFront(-i)
+	}
+	if s := d.String(); s != "[-5,-4,-3,-2,-1,1,2,3,4,5]" {
+		t.Fatalf("Unexpected deque %s", s)
+	}
+	if x, _ := d.Front(); x != -5 {
+		t.Errorf("Expected front -5, got %d", x)
+	}
+	if x, _ := d.Back(); x != 5 {
+		t.Errorf("Expected back 5, got %d", x)
+	}
+	if x, ok := d.PopFront(); !ok || x != -5 {
+		t.Errorf("PopFront() = %d, %v", x, ok)
+	}
+	if x, ok := d.PopBack(); !ok || x != 5 {
+		t.Errorf("PopBack() = %d, %v", x, ok)
+	}
+	d.Set(0, 40)
+	if d.At(0) != 40 || d.Len() != 8 {
+		t.Errorf("Unexpected deque %v", d)
+	}
+	var sum int
+	d.Range(func(x int) bool {
+		sum += x
+		return true
+	})
+	if sum != 40-3-2-1+1+2+3+4 {
+		t.Errorf("Unexpected sum %d", sum)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic for an index out of range")
+		}
+	}()
+	d.At(8)
+}
+
+func TestDeque_UndoRedo(t *testing.T) {
+	s := history.NewUndoStack()
+	d := history.NewDeque[string](s, 2)
+	var states []string
+	for _, op := range []func(){
+		func() { d.PushBack("a") },
+		func() { d.PushFront("b") },
+		func() { d.PushFront("c") }, // grows the ring buffer
+		func() { d.PopBack() },
+		func() { d.PushBack("d") },
+		func() { d.Set(1, "e") },
+		func() { d.PopFront() },
+		func() { d.Clear() },
+		func() { d.PushFront("f") },
+	} {
+		states = append(states, d.String())
+		op()
+	}
+	final := d.String()
+	for i := len(states) - 1; i >= 0; i-- {
+		s.Undo()
+		if got := d.String(); got != states[i] {
+			t.Errorf("Undo step %d: expected %s, got %s", len(states)-i, states[i], got)
+		}
+	}
+	for s.Redo() {
+	}
+	if d.String() != final {
+		t.Errorf("Expected %s after redo, got %s", final, d)
+	}
+}
+
+func TestDeque_Clone(t *testing.T) {
+	recorder := &history.BaseRecorder{}
+	d := history.NewDeque[int](recorder, 3)
+	d.PushBack(1)
+	d.PushBack(2)
+	d.PopFront()
+	d.PushBack(3)
+	d.PushBack(4) // wraps around
+	clone := d.Clone(&history.BaseRecorder{})
+	recorder.UndoAll()
+	if clone.String() != "[2,3,4]" || d.Len() != 0 {
+		t.Errorf("Expected independent clone, got %v and %v", clone, d)
+	}
+	clone.PushFront(1)
+	if clone.String() != "[1,2,3,4]" {
+		t.Errorf("Unexpected clone %v", clone)
+	}
+}
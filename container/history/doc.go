@@ -9,8 +9,20 @@
 //   - Map: A generic map that supports undo operations.
 //   - Set: A generic set that supports undo operations.
 //   - Slice: A generic slice that supports undo operations.
+//   - OrderedMap: A generic insertion-ordered map that supports undo operations.
+//   - Deque: A generic double-ended queue that supports undo operations.
+//   - PriorityQueue: A generic priority queue that supports undo operations.
+//   - Struct and Assign: Helpers which make assignments to struct fields undoable.
+//   - LockedRecorder, SyncMap, SyncSet and SyncSlice: Variants safe for concurrent use.
+//   - Isolated: Snapshot isolation between a writer and concurrent readers.
 //   - Recorder: An interface for managing undo actions.
 //   - BaseRecorder: A basic implementation of the Recorder interface.
+//   - UndoStack: A Recorder with redo, nested transactions and a bounded history.
+//   - ChangeLog: A log of container changes which can be exported as a Patch.
+//   - JournalWriter and JournalReader: A persistent journal of patches.
+//
+// The containers and recorders are not safe for concurrent use, unless stated
+// otherwise.
 //
 // Each data structure (Map, Set, and Slice) is designed to work with a Recorder,
 // which keeps track of changes and allows for undoing operations. The BaseRecorder
@@ -28,6 +40,42 @@
 //	_, ok := myMap.Get("key")
 //	fmt.Println(ok) // Output: false
 //
+// UndoStack groups the actions of a transaction so that they are undone and
+// redone together:
+//
+//	stack := history.NewUndoStack(history.WithMaxLength(100))
+//	units := history.NewMap[string, Point](stack, 0)
+//	stack.Begin("move units")
+//	units.Set("a", Point{1, 2})
+//	units.Set("b", Point{3, 4})
+//	stack.Commit()
+//	stack.Undo() // reverts both moves
+//	stack.Redo() // reapplies both moves
+//
+// For bulk operations, WithCoalescing records only the first of repeated sets
+// of a Map key within a transaction, and WithMemoryBudget bounds the memory
+// retained by the history, as estimated by the SizedAction interface. Clearing
+// a container hands its storage over to the recorded action instead of
+// copying it, and the container starts again with empty storage, so Clear and
+// undoing it take constant time and allocate no more than an empty map.
+//
+// Map, Set and Slice report their changes, including those made by undoing
+// and redoing actions, to subscribers as Change records. A ChangeLog collects
+// the changes so that the changes since a checkpoint can be sent to replicas
+// as a serializable Patch:
+//
+//	log := history.NewChangeLog[string, Point](units)
+//	...
+//	patch := log.Flush() // changes since the last flush
+//	data, _ := json.Marshal(patch)
+//	// on the replica
+//	replicaUnits.Apply(patch)
+//
+// Patches can be persisted by a JournalWriter, in JSON or in a binary format
+// by BinaryCodec or any other Codec. A container is rebuilt from a
+// snapshot and the journal by RestoreMap, RestoreSet or RestoreSlice, and an
+// existing container is rolled forward by RollForward.
+//
 // This package is useful for scenarios where you need to maintain a history of
 // changes and potentially revert them, such as in text editors, game state
 // management, or any application where an undo feature is desired.
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IsolatedContainer is the set of containers supported by Isolated, i.e. *Map,
+// *Set and *Slice.
+type IsolatedContainer[K, V any, C any] interface {
+	Observable[K, V]
+	Patcher[K, V]
+	Clone(recorder Recorder) C
+}
+
+// Isolated provides snapshot isolation for a container of type C. A single
+// writer at a time modifies a working copy of the container within a
+// transaction, while any number of readers concurrently see the state of the
+// container as of the last commit.
+//
+// Committing applies the changes of the transaction to the committed state,
+// which takes time proportional to the number of changes rather than to the
+// size of the container. Rolling back undoes the changes of the transaction.
+//
+//	units := history.NewIsolatedMap[string, Unit](0)
+//	// writer
+//	err := units.Update(func(m *history.Map[string, Unit]) error {
+//		m.Set("a", Unit{HP: 10})
+//		return nil
+//	})
+//	// readers
+//	units.View(func(m *history.Map[string, Unit]) {
+//		u, ok := m.Get("a")
+//		...
+//	})
+type Isolated[K, V any, C IsolatedContainer[K, V, C]] struct {
+	mu        sync.RWMutex // guards committed
+	committed C
+
+	writer   sync.Mutex // held during transactions
+	working  C
+	recorder BaseRecorder
+	log      *ChangeLog[K, V]
+}
+
+// NewIsolated creates a new Isolated whose initial state is a copy of c.
+func NewIsolated[K, V any, C IsolatedContainer[K, V, C]](c C) *Isolated[K, V, C] {
+	x := &Isolated[K, V, C]{}
+	x.committed = c.Clone(discardRecorder{})
+	x.working = c.Clone(&x.recorder)
+	x.log = NewChangeLog[K, V](x.working)
+	return x
+}
+
+// NewIsolatedMap creates a new Isolated Map with the given initial size.
+func NewIsolatedMap[K comparable, V any](size int) *Isolated[K, V, *Map[K, V]] {
+	return NewIsolated[K, V](NewMap[K, V](discardRecorder{}, size))
+}
+
+// NewIsolatedSet creates a new Isolated Set with the given initial size.
+func NewIsolatedSet[K comparable](size int) *Isolated[K, bool, *Set[K]] {
+	return NewIsolated[K, bool](NewSet[K](discardRecorder{}, size))
+}
+
+// NewIsolatedSlice creates a new Isolated Slice with the given length and capacity.
+func NewIsolatedSlice[T any](len, cap int) *Isolated[int, T, *Slice[T]] {
+	return NewIsolated[int, T](NewSlice[T](discardRecorder{}, len, cap))
+}
+
+// View calls fn with the committed state while holding a read lock. fn must
+// not modify the container. Commits wait for fn to return, so fn should be short.
+func (x *Isolated[K, V, C]) View(fn func(c C)) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	fn(x.committed)
+}
+
+// Begin starts a transaction and returns the working copy of the container,
+// which reflects the changes of the transaction. It blocks while another
+// transaction is in progress. The transaction must be ended by Commit or
+// Rollback, and the working copy must not be used after it ends.
+func (x *Isolated[K, V, C]) Begin() C {
+	x.writer.Lock()
+	return x.working
+}
+
+// Commit makes the changes of the transaction visible to readers and ends the
+// transaction.
+func (x *Isolated[K, V, C]) Commit() {
+	defer x.writer.Unlock()
+	patch := x.log.Flush()
+	clear(x.recorder.actions)
+	x.recorder.actions = x.recorder.actions[:0]
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if err := x.committed.Apply(patch); err != nil {
+		// the patch was produced by a copy of the same state
+		panic(fmt.Sprintf("history: failed to commit isolated changes: %v", err))
+	}
+}
+
+// Rollback discards the changes of the transaction and ends the transaction.
+func (x *Isolated[K, V, C]) Rollback() {
+	defer x.writer.Unlock()
+	x.recorder.UndoAll()
+	x.log.Flush()
+}
+
+// Update runs fn in a transaction. The transaction is committed if fn returns
+// nil, otherwise it's rolled back and the error is returned. If fn panics, the
+// transaction is rolled back and the panic is propagated.
+func (x *Isolated[K, V, C]) Update(fn func(c C) error) (err error) {
+	c := x.Begin()
+	committed := false
+	defer func() {
+		if !committed {
+			x.Rollback()
+		}
+	}()
+	if err = fn(c); err != nil {
+		return err
+	}
+	committed = true
+	x.Commit()
+	return nil
+}
+
+// discardRecorder is a Recorder which discards all actions.
+type discardRecorder struct{}
+
+func (discardRecorder) PushAction(Action) {}
+func (discardRecorder) UndoAll() int      { return 0 }
+func (discardRecorder) Undo() bool        { return false }
@@ -0,0 +1,112 @@
+package history_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestIsolated_Map(t *testing.T) {
+	units := history.NewIsolatedMap[string, int](0)
+	committed := func() string {
+		var s string
+		units.View(func(m *history.Map[string, int]) {
+			s = m.String()
+		})
+		return s
+	}
+
+	m := units.Begin()
+	m.Set("a", 1)
+	if got := committed(); got != "{}" {
+		t.Errorf("Expected readers not to see uncommitted changes, got %s", got)
+	}
+	units.Commit()
+	if got := committed(); got != "{a:1}" {
+		t.Errorf("Expected committed changes, got %s", got)
+	}
+
+	m = units.Begin()
+	m.Set("a", 2)
+	m.Clear()
+	units.Rollback()
+	if got := committed(); got != "{a:1}" {
+		t.Errorf("Expected rolled back changes to be invisible, got %s", got)
+	}
+	m = units.Begin()
+	if v, _ := m.Get("a"); v != 1 || m.Len() != 1 {
+		t.Errorf("Expected working copy to be rolled back, got %v", m)
+	}
+	units.Rollback()
+}
+
+func TestIsolated_Update(t *testing.T) {
+	s := history.NewIsolatedSet[int](0)
+	errFailed := errors.New("failed")
+	if err := s.Update(func(s *history.Set[int]) error {
+		s.Add(1)
+		return errFailed
+	}); err != errFailed {
+		t.Errorf("Expected error, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic to be propagated")
+			}
+		}()
+		s.Update(func(s *history.Set[int]) error {
+			s.Add(2)
+			panic("boom")
+		})
+	}()
+	if err := s.Update(func(s *history.Set[int]) error {
+		s.Add(3)
+		return nil
+	}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	s.View(func(s *history.Set[int]) {
+		if s.Len() != 1 || !s.Contains(3) {
+			t.Errorf("Expected only the committed element, got %v", s)
+		}
+	})
+}
+
+func TestIsolated_Concurrent(t *testing.T) {
+	sl := history.NewIsolatedSlice[int](0, 0)
+	const n = 200
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			// each transaction appends a pair which readers must see atomically
+			sl.Update(func(s *history.Slice[int]) error {
+				s.Append(i, i)
+				return nil
+			})
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				sl.View(func(s *history.Slice[int]) {
+					if s.Len()%2 != 0 {
+						t.Errorf("Expected committed pairs, got odd length %d", s.Len())
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	sl.View(func(s *history.Slice[int]) {
+		if s.Len() != 2*n {
+			t.Errorf("Expected %d elements, got %d", 2*n, s.Len())
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/gopherd/core/internal/codec"
+	"github.com/gopherd/core/internal/record"
+)
+
+// ErrCorruptJournal is the error returned when a journal record fails its
+// checksum or is larger than MaxJournalRecordSize.
+var ErrCorruptJournal = errors.New("history: corrupt journal record")
+
+// ErrJournalRecordTooLarge is the error returned when writing a patch whose
+// encoded record is larger than MaxJournalRecordSize.
+var ErrJournalRecordTooLarge = errors.New("history: journal record too large")
+
+// MaxJournalRecordSize is the maximum size in bytes of an encoded journal record.
+const MaxJournalRecordSize = 64 << 20
+
+// Codec marshals and unmarshals journal records and snapshots. It is the same
+// interface as event.Codec, so the codecs of both packages are interchangeable.
+type Codec = codec.Codec
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec = codec.JSON
+
+// GobCodec is a Codec using encoding/gob.
+type GobCodec = codec.Gob
+
+// BinaryCodec is a compact Codec which encodes values without field names or
+// type information, so readers must use the exact types the journal was
+// written with. It has the same format as event.BinaryCodec.
+type BinaryCodec = codec.Binary
+
+// JournalRecord is a patch stored in a journal.
+type JournalRecord[K, V any] struct {
+	// Seq is the sequence number of the record, starting from 1.
+	Seq uint64
+	// Changes are the changes of the record.
+	Changes Patch[K, V]
+}
+
+// Snapshot is the state of a container at a position of its journal. Its
+// State is the value returned by the Snapshot method of the container.
+type Snapshot[S any] struct {
+	// Seq is the sequence number of the last journal record included in the state.
+	Seq uint64
+	// State is the state of the container.
+	State S
+}
+
+// JournalOption represents an option for NewJournalWriter and NewJournalReader.
+type JournalOption func(*journalOptions)
+
+type journalOptions struct {
+	codec Codec
+	seq   uint64
+}
+
+func (o *journalOptions) apply(options []JournalOption) {
+	o.codec = JSONCodec{}
+	for _, opt := range options {
+		opt(o)
+	}
+}
+
+// WithCodec sets the codec of the journal records. The default is JSONCodec.
+// Readers must use the codec the journal was written with.
+func WithCodec(codec Codec) JournalOption {
+	return func(o *journalOptions) {
+		o.codec = codec
+	}
+}
+
+// WithSeq sets the sequence number of the last record already written, so
+// that a writer continues an existing journal. It's ignored by readers.
+func WithSeq(seq uint64) JournalOption {
+	return func(o *journalOptions) {
+		o.seq = seq
+	}
+}
+
+// JournalWriter writes patches of a container to an io.Writer as journal
+// records. Each record is stored as a 4-byte big-endian length, a 4-byte
+// CRC-32 (Castagnoli) checksum and the JournalRecord encoded by the codec.
+//
+// A typical use is flushing a ChangeLog at the end of each frame:
+//
+//	w := history.NewJournalWriter[string, Unit](file)
+//	log := history.NewChangeLog[string, Unit](units)
+//	...
+//	if _, err := w.Write(log.Flush()); err != nil {
+//		return err
+//	}
+type JournalWriter[K, V any] struct {
+	w       io.Writer
+	options journalOptions
+}
+
+// NewJournalWriter creates a new JournalWriter which writes to w.
+func NewJournalWriter[K, V any](w io.Writer, options ...JournalOption) *JournalWriter[K, V] {
+	jw := &JournalWriter[K, V]{w: w}
+	jw.options.apply(options)
+	return jw
+}
+
+// Seq returns the sequence number of the last record written.
+func (w *JournalWriter[K, V]) Seq() uint64 {
+	return w.options.seq
+}
+
+// Write writes the patch as a new record and returns the sequence number of
+// the record. Empty patches are not written and the current sequence number is
+// returned. A patch whose encoded record is larger than MaxJournalRecordSize is
+// rejected with ErrJournalRecordTooLarge before anything is written.
+func (w *JournalWriter[K, V]) Write(patch Patch[K, V]) (uint64, error) {
+	if len(patch) == 0 {
+		return w.options.seq, nil
+	}
+	seq := w.options.seq + 1
+	data, err := w.options.codec.Marshal(JournalRecord[K, V]{Seq: seq, Changes: patch})
+	if err != nil {
+		return w.options.seq, err
+	}
+	if len(data) > MaxJournalRecordSize {
+		return w.options.seq, fmt.Errorf("%w: %d bytes", ErrJournalRecordTooLarge, len(data))
+	}
+	buf := record.Append(make([]byte, 0, record.HeaderSize+len(data)), data)
+	if _, err := w.w.Write(buf); err != nil {
+		return w.options.seq, err
+	}
+	w.options.seq = seq
+	return seq, nil
+}
+
+// JournalReader reads the records written by a JournalWriter.
+type JournalReader[K, V any] struct {
+	r       io.Reader
+	options journalOptions
+}
+
+// NewJournalReader creates a new JournalReader which reads from r.
+func NewJournalReader[K, V any](r io.Reader, options ...JournalOption) *JournalReader[K, V] {
+	jr := &JournalReader[K, V]{r: r}
+	jr.options.apply(options)
+	return jr
+}
+
+// Next reads the next record. It returns io.EOF at the end of the journal,
+// io.ErrUnexpectedEOF if the last record is truncated and ErrCorruptJournal if
+// a record fails its checksum or is too large.
+func (r *JournalReader[K, V]) Next() (JournalRecord[K, V], error) {
+	var rec JournalRecord[K, V]
+	data, err := record.Read(r.r, MaxJournalRecordSize)
+	if err != nil {
+		if err == record.ErrCorrupt {
+			err = ErrCorruptJournal
+		}
+		return rec, err
+	}
+	if err := r.options.codec.Unmarshal(data, &rec); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+// Patcher is implemented by containers which can apply a Patch.
+type Patcher[K, V any] interface {
+	Apply(patch Patch[K, V]) error
+}
+
+// RollForward applies the records of the journal with sequence numbers greater
+// than seq to dst, in order. It returns the sequence number of the last record
+// applied, or seq if none was applied.
+func RollForward[K, V any](dst Patcher[K, V], r *JournalReader[K, V], seq uint64) (uint64, error) {
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return seq, nil
+		}
+		if err != nil {
+			return seq, err
+		}
+		if record.Seq <= seq {
+			continue
+		}
+		if record.Seq != seq+1 {
+			return seq, fmt.Errorf("history: journal record %d follows record %d", record.Seq, seq)
+		}
+		if err := dst.Apply(record.Changes); err != nil {
+			return seq, fmt.Errorf("history: journal record %d: %w", record.Seq, err)
+		}
+		seq = record.Seq
+	}
+}
+
+// RestoreMap creates a Map from the snapshot and rolls it forward with the
+// journal. The restored changes are recorded by the recorder.
+func RestoreMap[K comparable, V any](recorder Recorder, snapshot Snapshot[map[K]V], r *JournalReader[K, V]) (*Map[K, V], uint64, error) {
+	m := &Map[K, V]{recorder: recorder, data: maps.Clone(snapshot.State)}
+	if m.data == nil {
+		m.data = make(map[K]V)
+	}
+	seq, err := RollForward[K, V](m, r, snapshot.Seq)
+	return m, seq, err
+}
+
+// RestoreSet creates a Set from the snapshot and rolls it forward with the
+// journal. The restored changes are recorded by the recorder.
+func RestoreSet[K comparable](recorder Recorder, snapshot Snapshot[[]K], r *JournalReader[K, bool]) (*Set[K], uint64, error) {
+	s := NewSet[K](recorder, len(snapshot.State))
+	for _, k := range snapshot.State {
+		s.data[k] = struct{}{}
+	}
+	seq, err := RollForward[K, bool](s, r, snapshot.Seq)
+	return s, seq, err
+}
+
+// RestoreSlice creates a Slice from the snapshot and rolls it forward with the
+// journal. The restored changes are recorded by the recorder.
+func RestoreSlice[T any](recorder Recorder, snapshot Snapshot[[]T], r *JournalReader[int, T]) (*Slice[T], uint64, error) {
+	s := &Slice[T]{recorder: recorder, data: slices.Clone(snapshot.State)}
+	seq, err := RollForward[int, T](s, r, snapshot.Seq)
+	return s, seq, err
+}
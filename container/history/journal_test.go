@@ -0,0 +1,204 @@
+package history_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestJournal_Map(t *testing.T) {
+	for name, codec := range map[string]history.Codec{
+		"json":   history.JSONCodec{},
+		"binary": history.BinaryCodec{},
+		"gob":    history.GobCodec{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := history.NewUndoStack()
+			m := history.NewMap[string, int](s, 0)
+			log := history.NewChangeLog[string, int](m)
+			w := history.NewJournalWriter[string, int](&buf, history.WithCodec(codec))
+
+			m.Set("a", 1)
+			m.Set("b", 2)
+			if seq, err := w.Write(log.Flush()); err != nil || seq != 1 {
+				t.Fatalf("Write() = %d, %v", seq, err)
+			}
+			snapshot := history.Snapshot[map[string]int]{Seq: w.Seq(), State: m.Snapshot()}
+			m.Set("a", 3)
+			m.Remove("b")
+			w.Write(log.Flush())
+			m.Clear()
+			m.Set("c", 4)
+			s.Undo()
+			w.Write(log.Flush())
+			if seq, err := w.Write(log.Flush()); err != nil || seq != 3 {
+				t.Errorf("Expected empty patch not to be written, got %d, %v", seq, err)
+			}
+
+			data, err := codec.Marshal(snapshot)
+			if err != nil {
+				t.Fatalf("Marshal snapshot failed: %v", err)
+			}
+			var decoded history.Snapshot[map[string]int]
+			if err := codec.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal snapshot failed: %v", err)
+			}
+
+			r := history.NewJournalReader[string, int](bytes.NewReader(buf.Bytes()), history.WithCodec(codec))
+			restored, seq, err := history.RestoreMap(&history.BaseRecorder{}, decoded, r)
+			if err != nil || seq != 3 {
+				t.Fatalf("RestoreMap() = %d, %v", seq, err)
+			}
+			if got, want := restored.Snapshot(), m.Snapshot(); !maps.Equal(got, want) {
+				t.Errorf("Expected restored %v, got %v", want, got)
+			}
+
+			// rebuild from scratch with the whole journal
+			r = history.NewJournalReader[string, int](bytes.NewReader(buf.Bytes()), history.WithCodec(codec))
+			rebuilt, _, err := history.RestoreMap(&history.BaseRecorder{}, history.Snapshot[map[string]int]{}, r)
+			if err != nil {
+				t.Fatalf("RestoreMap() failed: %v", err)
+			}
+			if got, want := rebuilt.Snapshot(), m.Snapshot(); !maps.Equal(got, want) {
+				t.Errorf("Expected rebuilt %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestJournal_SetAndSlice(t *testing.T) {
+	var setBuf, sliceBuf bytes.Buffer
+	recorder := &history.BaseRecorder{}
+	set := history.NewSet[string](recorder, 0)
+	sl := history.NewSlice[float64](recorder, 0, 0)
+	setLog := history.NewChangeLog[string, bool](set)
+	sliceLog := history.NewChangeLog[int, float64](sl)
+	setWriter := history.NewJournalWriter[string, bool](&setBuf, history.WithCodec(history.BinaryCodec{}))
+	sliceWriter := history.NewJournalWriter[int, float64](&sliceBuf, history.WithCodec(history.BinaryCodec{}))
+
+	set.Add("x")
+	sl.Append(1.5, 2.5)
+	setWriter.Write(setLog.Flush())
+	sliceWriter.Write(sliceLog.Flush())
+	setSnapshot := history.Snapshot[[]string]{Seq: setWriter.Seq(), State: set.Snapshot()}
+	sliceSnapshot := history.Snapshot[[]float64]{Seq: sliceWriter.Seq(), State: sl.Snapshot()}
+
+	set.Add("y")
+	set.Remove("x")
+	sl.Insert(1, 2)
+	sl.Reverse()
+	setWriter.Write(setLog.Flush())
+	sliceWriter.Write(sliceLog.Flush())
+
+	restoredSet, _, err := history.RestoreSet(recorder, setSnapshot,
+		history.NewJournalReader[string, bool](&setBuf, history.WithCodec(history.BinaryCodec{})))
+	if err != nil {
+		t.Fatalf("RestoreSet() failed: %v", err)
+	}
+	if restoredSet.String() != set.String() {
+		t.Errorf("Expected restored set %v, got %v", set, restoredSet)
+	}
+	restoredSlice, _, err := history.RestoreSlice(recorder, sliceSnapshot,
+		history.NewJournalReader[int, float64](&sliceBuf, history.WithCodec(history.BinaryCodec{})))
+	if err != nil {
+		t.Fatalf("RestoreSlice() failed: %v", err)
+	}
+	if !slices.Equal(restoredSlice.Snapshot(), sl.Snapshot()) {
+		t.Errorf("Expected restored slice %v, got %v", sl, restoredSlice)
+	}
+}
+
+func TestJournal_Continue(t *testing.T) {
+	var buf bytes.Buffer
+	m := history.NewMap[int, int](&history.BaseRecorder{}, 0)
+	w := history.NewJournalWriter[int, int](&buf)
+	w.Write(history.Patch[int, int]{{Kind: history.ChangeSet, Key: 1, New: 1}})
+
+	// a new writer continues the journal after a restart
+	w = history.NewJournalWriter[int, int](&buf, history.WithSeq(w.Seq()))
+	w.Write(history.Patch[int, int]{{Kind: history.ChangeSet, Key: 2, New: 2}})
+
+	seq, err := history.RollForward[int, int](m, history.NewJournalReader[int, int](bytes.NewReader(buf.Bytes())), 0)
+	if err != nil || seq != 2 || m.Len() != 2 {
+		t.Errorf("RollForward() = %d, %v with %v", seq, err, m)
+	}
+
+	// records already included in the state are skipped
+	m = history.NewMap[int, int](&history.BaseRecorder{}, 0)
+	seq, err = history.RollForward[int, int](m, history.NewJournalReader[int, int](bytes.NewReader(buf.Bytes())), 1)
+	if err != nil || seq != 2 || m.Len() != 1 || !m.Contains(2) {
+		t.Errorf("RollForward() = %d, %v with %v", seq, err, m)
+	}
+}
+
+func TestJournal_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	w := history.NewJournalWriter[int, int](&buf)
+	w.Write(history.Patch[int, int]{{Kind: history.ChangeSet, Key: 1, New: 1}})
+	data := buf.Bytes()
+	m := history.NewMap[int, int](&history.BaseRecorder{}, 0)
+
+	t.Run("truncated", func(t *testing.T) {
+		r := history.NewJournalReader[int, int](bytes.NewReader(data[:len(data)-1]))
+		if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		corrupt := bytes.Clone(data)
+		corrupt[len(corrupt)-2] ^= 0xff
+		r := history.NewJournalReader[int, int](bytes.NewReader(corrupt))
+		if _, err := history.RollForward[int, int](m, r, 0); !errors.Is(err, history.ErrCorruptJournal) {
+			t.Errorf("Expected ErrCorruptJournal, got %v", err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		// a length prefix of 4 GiB must be rejected before allocating
+		header := []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}
+		r := history.NewJournalReader[int, int](bytes.NewReader(header))
+		if _, err := r.Next(); !errors.Is(err, history.ErrCorruptJournal) {
+			t.Errorf("Expected ErrCorruptJournal, got %v", err)
+		}
+	})
+
+	t.Run("write too large", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := history.NewJournalWriter[int, string](&buf)
+		seq, err := w.Write(history.Patch[int, string]{{Kind: history.ChangeSet, Key: 1, New: strings.Repeat("x", history.MaxJournalRecordSize)}})
+		if !errors.Is(err, history.ErrJournalRecordTooLarge) {
+			t.Errorf("Expected ErrJournalRecordTooLarge, got %v", err)
+		}
+		if seq != 0 || w.Seq() != 0 || buf.Len() != 0 {
+			t.Errorf("Expected nothing written, got seq %d, %d bytes", seq, buf.Len())
+		}
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		var gap bytes.Buffer
+		w := history.NewJournalWriter[int, int](&gap, history.WithSeq(5))
+		w.Write(history.Patch[int, int]{{Kind: history.ChangeSet, Key: 1, New: 1}})
+		r := history.NewJournalReader[int, int](&gap)
+		if seq, err := history.RollForward[int, int](m, r, 0); err == nil || seq != 0 {
+			t.Errorf("Expected error for missing records, got %d, %v", seq, err)
+		}
+	})
+
+	t.Run("invalid change", func(t *testing.T) {
+		var invalid bytes.Buffer
+		w := history.NewJournalWriter[int, int](&invalid)
+		w.Write(history.Patch[int, int]{{Kind: history.ChangeReverse}})
+		r := history.NewJournalReader[int, int](&invalid)
+		if _, err := history.RollForward[int, int](m, r, 0); err == nil {
+			t.Errorf("Expected error for a change which can't be applied")
+		}
+	})
+}
@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"unsafe"
 )
 
 // Map is a generic map with keys of type K and values of type V that supports undo operations.
 type Map[K comparable, V any] struct {
-	recorder Recorder
-	data     map[K]V
+	recorder  Recorder
+	data      map[K]V
+	observers observers[Change[K, V]]
+
+	// keys set in the transaction of generation gen, see coalescer
+	gen       uint64
+	coalesced map[K]struct{}
 }
 
 // NewMap creates a new Map with the given recorder and initial size.
@@ -45,6 +51,12 @@ func (m *Map[K, V]) Clone(recorder Recorder) *Map[K, V] {
 	}
 }
 
+// Snapshot returns a copy of the entries of the Map, e.g. to be stored with
+// a journal and restored by RestoreMap.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	return maps.Clone(m.data)
+}
+
 // Len returns the number of elements in the Map.
 func (m *Map[K, V]) Len() int {
 	return len(m.data)
@@ -66,38 +78,77 @@ func (m *Map[K, V]) Get(k K) (v V, ok bool) {
 // It returns true if an existing entry was updated.
 func (m *Map[K, V]) Set(k K, v V) bool {
 	old, replaced := m.data[k]
-	if replaced {
-		m.recorder.PushAction(&mapUndoSetAction[K, V]{m: m, k: k, v: old, replaced: true})
-	} else {
-		m.recorder.PushAction(&mapUndoSetAction[K, V]{m: m, k: k})
+	if !m.coalesce(k) {
+		m.recorder.PushAction(&mapUndoSetAction[K, V]{m: m, k: k, v: old, replaced: replaced})
 	}
 	m.data[k] = v
+	m.observers.notify(Change[K, V]{Kind: ChangeSet, Key: k, Old: old, New: v, Replaced: replaced})
 	return replaced
 }
 
+// coalesce reports whether setting k needs no action because an earlier
+// action of the transaction in progress restores it, and otherwise tracks k.
+func (m *Map[K, V]) coalesce(k K) bool {
+	c, ok := m.recorder.(coalescer)
+	if !ok {
+		return false
+	}
+	gen, ok := c.coalescing()
+	if !ok {
+		return false
+	}
+	if gen != m.gen || m.coalesced == nil {
+		m.gen = gen
+		if m.coalesced == nil {
+			m.coalesced = make(map[K]struct{})
+		}
+		clear(m.coalesced)
+	}
+	if _, ok := m.coalesced[k]; ok {
+		return true
+	}
+	m.coalesced[k] = struct{}{}
+	return false
+}
+
 type mapUndoSetAction[K comparable, V any] struct {
 	m        *Map[K, V]
 	k        K
 	v        V
 	replaced bool
+	newValue V
 }
 
 func (r *mapUndoSetAction[K, V]) Undo() {
+	r.newValue = r.m.data[r.k]
 	if r.replaced {
 		r.m.data[r.k] = r.v
+		r.m.observers.notify(Change[K, V]{Kind: ChangeSet, Key: r.k, Old: r.newValue, New: r.v, Replaced: true})
 	} else {
 		delete(r.m.data, r.k)
+		r.m.observers.notify(Change[K, V]{Kind: ChangeRemove, Key: r.k, Old: r.newValue})
 	}
 }
 
+func (r *mapUndoSetAction[K, V]) Redo() {
+	r.m.data[r.k] = r.newValue
+	r.m.observers.notify(Change[K, V]{Kind: ChangeSet, Key: r.k, Old: r.v, New: r.newValue, Replaced: r.replaced})
+}
+
+func (r *mapUndoSetAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
 // Remove removes a key-value pair from the Map.
 // It returns the removed value and a boolean indicating if the key was present.
 func (m *Map[K, V]) Remove(k K) (v V, removed bool) {
 	v, removed = m.data[k]
 	if removed {
 		m.recorder.PushAction(&mapUndoRemoveAction[K, V]{m: m, k: k, v: v})
+		delete(m.coalesced, k)
+		delete(m.data, k)
+		m.observers.notify(Change[K, V]{Kind: ChangeRemove, Key: k, Old: v})
 	}
-	delete(m.data, k)
 	return
 }
 
@@ -109,6 +160,16 @@ type mapUndoRemoveAction[K comparable, V any] struct {
 
 func (r *mapUndoRemoveAction[K, V]) Undo() {
 	r.m.data[r.k] = r.v
+	r.m.observers.notify(Change[K, V]{Kind: ChangeSet, Key: r.k, New: r.v})
+}
+
+func (r *mapUndoRemoveAction[K, V]) Redo() {
+	delete(r.m.data, r.k)
+	r.m.observers.notify(Change[K, V]{Kind: ChangeRemove, Key: r.k, Old: r.v})
+}
+
+func (r *mapUndoRemoveAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // Range calls f sequentially for each key and value in the Map.
@@ -122,10 +183,14 @@ func (m *Map[K, V]) Range(f func(K, V) bool) bool {
 	return true
 }
 
-// Clear removes all elements from the Map.
+// Clear removes all elements from the Map. The entries are handed over to the
+// recorded action instead of being copied, and the Map starts again with an
+// empty map, so Clear takes constant time.
 func (m *Map[K, V]) Clear() {
-	m.recorder.PushAction(&mapUndoClearAction[K, V]{m: m, values: maps.Clone(m.data)})
-	clear(m.data)
+	m.recorder.PushAction(&mapUndoClearAction[K, V]{m: m, values: m.data})
+	clear(m.coalesced)
+	m.data = make(map[K]V)
+	m.observers.notify(Change[K, V]{Kind: ChangeClear})
 }
 
 type mapUndoClearAction[K comparable, V any] struct {
@@ -135,4 +200,42 @@ type mapUndoClearAction[K comparable, V any] struct {
 
 func (r *mapUndoClearAction[K, V]) Undo() {
 	r.m.data = r.values
+	for k, v := range r.values {
+		r.m.observers.notify(Change[K, V]{Kind: ChangeSet, Key: k, New: v})
+	}
+}
+
+func (r *mapUndoClearAction[K, V]) Redo() {
+	r.values = r.m.data
+	r.m.data = make(map[K]V)
+	r.m.observers.notify(Change[K, V]{Kind: ChangeClear})
+}
+
+func (r *mapUndoClearAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r)) + len(r.values)*(sizeOf[K]()+sizeOf[V]())
+}
+
+// Subscribe implements the Observable interface. The changes are reported as
+// described by Change.
+func (m *Map[K, V]) Subscribe(fn func(Change[K, V])) (unsubscribe func()) {
+	return m.observers.subscribe(fn)
+}
+
+// Apply applies the changes of the patch to the Map, recording them as any
+// other mutation. It returns an error if the patch contains a change which
+// can't be applied to a Map.
+func (m *Map[K, V]) Apply(patch Patch[K, V]) error {
+	for i, c := range patch {
+		switch c.Kind {
+		case ChangeSet:
+			m.Set(c.Key, c.New)
+		case ChangeRemove:
+			m.Remove(c.Key)
+		case ChangeClear:
+			m.Clear()
+		default:
+			return fmt.Errorf("history: change %d: unexpected %v change for map", i, c.Kind)
+		}
+	}
+	return nil
 }
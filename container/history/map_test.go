@@ -290,3 +290,21 @@ func TestMap_ClearThenUndo(t *testing.T) {
 		t.Errorf("After Undo Clear, expected map with 'a' and 'b', got %v", m)
 	}
 }
+
+func BenchmarkMap_ClearUndo(b *testing.B) {
+	recorder := &history.BaseRecorder{}
+	m := history.NewMap[int, int](recorder, 1000)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	recorder.UndoAll()
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Clear()
+		recorder.Undo()
+	}
+}
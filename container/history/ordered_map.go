@@ -0,0 +1,209 @@
+package history
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// OrderedMap is a generic map which remembers the insertion order of its keys
+// and supports undo operations. Updating the value of an existing key doesn't
+// change its position.
+//
+// Removing a key takes O(n) time, while the other operations take the same
+// time as for a Map.
+type OrderedMap[K comparable, V any] struct {
+	recorder Recorder
+	keys     []K
+	data     map[K]V
+}
+
+// NewOrderedMap creates a new OrderedMap with the given recorder and initial size.
+func NewOrderedMap[K comparable, V any](recorder Recorder, size int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		recorder: recorder,
+		keys:     make([]K, 0, size),
+		data:     make(map[K]V, size),
+	}
+}
+
+// String returns a string representation of the OrderedMap in insertion order.
+func (m OrderedMap[K, V]) String() string {
+	var sb strings.Builder
+	sb.Grow(len(m.keys)*18 + 1)
+	sb.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, "%v:%v", k, m.data[k])
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// Clone creates a deep copy of the OrderedMap with a new recorder.
+func (m *OrderedMap[K, V]) Clone(recorder Recorder) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		recorder: recorder,
+		keys:     slices.Clone(m.keys),
+		data:     maps.Clone(m.data),
+	}
+}
+
+// Len returns the number of elements in the OrderedMap.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Contains checks if the OrderedMap contains the given key.
+func (m *OrderedMap[K, V]) Contains(k K) bool {
+	_, ok := m.data[k]
+	return ok
+}
+
+// Get retrieves the value for a key in the OrderedMap.
+func (m *OrderedMap[K, V]) Get(k K) (v V, ok bool) {
+	v, ok = m.data[k]
+	return
+}
+
+// At returns the i-th key-value pair in insertion order.
+func (m *OrderedMap[K, V]) At(i int) (K, V) {
+	k := m.keys[i]
+	return k, m.data[k]
+}
+
+// Index returns the position of the key in insertion order, or -1 if the key
+// is not present. It takes O(n) time.
+func (m *OrderedMap[K, V]) Index(k K) int {
+	if _, ok := m.data[k]; !ok {
+		return -1
+	}
+	return slices.Index(m.keys, k)
+}
+
+// Set adds or updates a key-value pair in the OrderedMap. A new key is added
+// at the end. It returns true if an existing entry was updated.
+func (m *OrderedMap[K, V]) Set(k K, v V) bool {
+	old, replaced := m.data[k]
+	m.recorder.PushAction(&orderedMapUndoSetAction[K, V]{m: m, k: k, v: old, replaced: replaced})
+	if !replaced {
+		m.keys = append(m.keys, k)
+	}
+	m.data[k] = v
+	return replaced
+}
+
+type orderedMapUndoSetAction[K comparable, V any] struct {
+	m        *OrderedMap[K, V]
+	k        K
+	v        V
+	replaced bool
+	newValue V
+}
+
+func (r *orderedMapUndoSetAction[K, V]) Undo() {
+	r.newValue = r.m.data[r.k]
+	if r.replaced {
+		r.m.data[r.k] = r.v
+		return
+	}
+	// the key was appended by the action, so it's the last one
+	delete(r.m.data, r.k)
+	var zero K
+	r.m.keys[len(r.m.keys)-1] = zero
+	r.m.keys = r.m.keys[:len(r.m.keys)-1]
+}
+
+func (r *orderedMapUndoSetAction[K, V]) Redo() {
+	if !r.replaced {
+		r.m.keys = append(r.m.keys, r.k)
+	}
+	r.m.data[r.k] = r.newValue
+}
+
+func (r *orderedMapUndoSetAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
+// Remove removes a key-value pair from the OrderedMap.
+// It returns the removed value and a boolean indicating if the key was present.
+func (m *OrderedMap[K, V]) Remove(k K) (v V, removed bool) {
+	v, removed = m.data[k]
+	if removed {
+		i := slices.Index(m.keys, k)
+		m.recorder.PushAction(&orderedMapUndoRemoveAction[K, V]{m: m, i: i, k: k, v: v})
+		m.keys = slices.Delete(m.keys, i, i+1)
+		delete(m.data, k)
+	}
+	return
+}
+
+type orderedMapUndoRemoveAction[K comparable, V any] struct {
+	m *OrderedMap[K, V]
+	i int
+	k K
+	v V
+}
+
+func (r *orderedMapUndoRemoveAction[K, V]) Undo() {
+	r.m.keys = slices.Insert(r.m.keys, r.i, r.k)
+	r.m.data[r.k] = r.v
+}
+
+func (r *orderedMapUndoRemoveAction[K, V]) Redo() {
+	r.m.keys = slices.Delete(r.m.keys, r.i, r.i+1)
+	delete(r.m.data, r.k)
+}
+
+func (r *orderedMapUndoRemoveAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r))
+}
+
+// Range calls f sequentially for each key and value in the OrderedMap in
+// insertion order. If f returns false, Range stops the iteration.
+func (m *OrderedMap[K, V]) Range(f func(K, V) bool) bool {
+	for _, k := range m.keys {
+		if !f(k, m.data[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all elements from the OrderedMap. The keys and entries are
+// handed over to the recorded action instead of being copied, and the
+// OrderedMap starts again with an empty map and no key slice.
+func (m *OrderedMap[K, V]) Clear() {
+	m.recorder.PushAction(&orderedMapUndoClearAction[K, V]{m: m, keys: m.keys, values: m.data})
+	m.clear()
+}
+
+func (m *OrderedMap[K, V]) clear() {
+	m.keys = nil
+	m.data = make(map[K]V)
+}
+
+type orderedMapUndoClearAction[K comparable, V any] struct {
+	m      *OrderedMap[K, V]
+	keys   []K
+	values map[K]V
+}
+
+func (r *orderedMapUndoClearAction[K, V]) Undo() {
+	r.m.keys = r.keys
+	r.m.data = r.values
+}
+
+func (r *orderedMapUndoClearAction[K, V]) Redo() {
+	r.keys = r.m.keys
+	r.values = r.m.data
+	r.m.clear()
+}
+
+func (r *orderedMapUndoClearAction[K, V]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.keys)*sizeOf[K]() + len(r.values)*(sizeOf[K]()+sizeOf[V]())
+}
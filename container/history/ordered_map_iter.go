@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package history
+
+import (
+	"iter"
+	"slices"
+)
+
+// All returns an iterator over key-value pairs in the map in insertion order
+func (m OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, k := range m.keys {
+			if !yield(k, m.data[k]) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the map keys in insertion order
+func (m OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return slices.Values(m.keys)
+}
+
+// Values returns an iterator over the map values in insertion order
+func (m OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, k := range m.keys {
+			if !yield(m.data[k]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package history_test
+
+import (
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestOrderedMap_Order(t *testing.T) {
+	m := history.NewOrderedMap[string, int](&history.BaseRecorder{}, 0)
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("c", 4)
+
+	if s := m.String(); s != "{c:4,a:2,b:3}" {
+		t.Errorf("Expected insertion order, got %s", s)
+	}
+	if k, v := m.At(1); k != "a" || v != 2 {
+		t.Errorf("Expected a:2 at 1, got %s:%d", k, v)
+	}
+	if m.Index("b") != 2 || m.Index("x") != -1 {
+		t.Errorf("Unexpected indexes %d, %d", m.Index("b"), m.Index("x"))
+	}
+	if v, ok := m.Remove("a"); !ok || v != 2 {
+		t.Errorf("Remove() = %d, %v", v, ok)
+	}
+	if _, ok := m.Remove("a"); ok {
+		t.Errorf("Expected missing key not to be removed")
+	}
+	var keys []string
+	m.Range(func(k string, v int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if len(keys) != 2 || keys[0] != "c" || keys[1] != "b" {
+		t.Errorf("Expected keys [c b], got %v", keys)
+	}
+	m.Set("a", 5)
+	if s := m.String(); s != "{c:4,b:3,a:5}" {
+		t.Errorf("Expected re-added key at the end, got %s", s)
+	}
+	if !m.Range(func(string, int) bool { return true }) || m.Range(func(string, int) bool { return false }) {
+		t.Errorf("Unexpected Range results")
+	}
+}
+
+func TestOrderedMap_UndoRedo(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewOrderedMap[string, int](s, 0)
+	var states []string
+	for _, op := range []func(){
+		func() { m.Set("a", 1) },
+		func() { m.Set("b", 2) },
+		func() { m.Set("c", 3) },
+		func() { m.Set("a", 4) },
+		func() { m.Remove("b") },
+		func() { m.Clear() },
+		func() { m.Set("d", 5) },
+	} {
+		states = append(states, m.String())
+		op()
+	}
+	final := m.String()
+	for i := len(states) - 1; i >= 0; i-- {
+		s.Undo()
+		if got := m.String(); got != states[i] {
+			t.Errorf("Undo step %d: expected %s, got %s", len(states)-i, states[i], got)
+		}
+	}
+	for s.Redo() {
+	}
+	if m.String() != final {
+		t.Errorf("Expected %s after redo, got %s", final, m)
+	}
+}
+
+func TestOrderedMap_Clone(t *testing.T) {
+	recorder := &history.BaseRecorder{}
+	m := history.NewOrderedMap[int, string](recorder, 2)
+	m.Set(2, "b")
+	m.Set(1, "a")
+	clone := m.Clone(&history.BaseRecorder{})
+	m.Set(3, "c")
+	recorder.UndoAll()
+	if clone.String() != "{2:b,1:a}" || m.Len() != 0 {
+		t.Errorf("Expected independent clone, got %v and %v", clone, m)
+	}
+	if v, ok := clone.Get(1); !ok || v != "a" || !clone.Contains(2) {
+		t.Errorf("Unexpected clone content %v", clone)
+	}
+}
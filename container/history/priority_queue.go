@@ -0,0 +1,207 @@
+package history
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/gopherd/core/container/heap"
+)
+
+// PriorityQueue is a generic priority queue built on container/heap that
+// supports undo operations. The element popped first is the least one
+// according to the less function.
+//
+// Undoing an operation restores the exact layout of the heap, so the order in
+// which elements of equal priority are popped is reproducible.
+type PriorityQueue[T any] struct {
+	recorder Recorder
+	heap     priorityHeap[T]
+}
+
+// priorityHeap implements the heap.Interface interface and records the swaps
+// made by the heap operations so that they can be reverted.
+type priorityHeap[T any] struct {
+	data  []T
+	less  func(a, b T) bool
+	swaps []int
+}
+
+func (h *priorityHeap[T]) Len() int           { return len(h.data) }
+func (h *priorityHeap[T]) Less(i, j int) bool { return h.less(h.data[i], h.data[j]) }
+func (h *priorityHeap[T]) Push(x T)           { h.data = append(h.data, x) }
+
+func (h *priorityHeap[T]) Swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+	h.swaps = append(h.swaps, i, j)
+}
+
+func (h *priorityHeap[T]) Pop() T {
+	var zero T
+	n := len(h.data) - 1
+	x := h.data[n]
+	h.data[n] = zero
+	h.data = h.data[:n]
+	return x
+}
+
+// push pushes x and returns the swaps made.
+func (h *priorityHeap[T]) push(x T) []int {
+	h.swaps = h.swaps[:0]
+	heap.Push[T](h, x)
+	return slices.Clone(h.swaps)
+}
+
+// pop pops the least element and returns it with the swaps made.
+func (h *priorityHeap[T]) pop() (T, []int) {
+	h.swaps = h.swaps[:0]
+	x := heap.Pop[T](h)
+	return x, slices.Clone(h.swaps)
+}
+
+// unswap reverts the swaps in reverse order.
+func (h *priorityHeap[T]) unswap(swaps []int) {
+	for k := len(swaps) - 2; k >= 0; k -= 2 {
+		i, j := swaps[k], swaps[k+1]
+		h.data[i], h.data[j] = h.data[j], h.data[i]
+	}
+}
+
+// NewPriorityQueue creates a new PriorityQueue with the given recorder, less
+// function and initial capacity.
+func NewPriorityQueue[T any](recorder Recorder, less func(a, b T) bool, cap int) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		recorder: recorder,
+		heap:     priorityHeap[T]{data: make([]T, 0, cap), less: less},
+	}
+}
+
+// String returns a string representation of the PriorityQueue in heap order.
+func (q PriorityQueue[T]) String() string {
+	var sb strings.Builder
+	sb.Grow(len(q.heap.data)*9 + 1)
+	sb.WriteByte('[')
+	for i, x := range q.heap.data {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprint(&sb, x)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// Clone creates a deep copy of the PriorityQueue with a new recorder.
+func (q *PriorityQueue[T]) Clone(recorder Recorder) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		recorder: recorder,
+		heap:     priorityHeap[T]{data: slices.Clone(q.heap.data), less: q.heap.less},
+	}
+}
+
+// Len returns the number of elements in the PriorityQueue.
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.heap.data)
+}
+
+// Peek returns the least element without removing it. It returns false if the
+// PriorityQueue is empty.
+func (q *PriorityQueue[T]) Peek() (x T, ok bool) {
+	if len(q.heap.data) == 0 {
+		return
+	}
+	return q.heap.data[0], true
+}
+
+// Push adds x to the PriorityQueue.
+func (q *PriorityQueue[T]) Push(x T) {
+	swaps := q.heap.push(x)
+	q.recorder.PushAction(&priorityQueueUndoPushAction[T]{q: q, x: x, swaps: swaps})
+}
+
+type priorityQueueUndoPushAction[T any] struct {
+	q     *PriorityQueue[T]
+	x     T
+	swaps []int
+}
+
+func (r *priorityQueueUndoPushAction[T]) Undo() {
+	r.q.heap.unswap(r.swaps)
+	r.q.heap.Pop()
+}
+
+func (r *priorityQueueUndoPushAction[T]) Redo() {
+	r.swaps = r.q.heap.push(r.x)
+}
+
+func (r *priorityQueueUndoPushAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.swaps)*sizeOf[int]()
+}
+
+// Pop removes and returns the least element. It returns false if the
+// PriorityQueue is empty.
+func (q *PriorityQueue[T]) Pop() (x T, ok bool) {
+	if len(q.heap.data) == 0 {
+		return
+	}
+	x, swaps := q.heap.pop()
+	q.recorder.PushAction(&priorityQueueUndoPopAction[T]{q: q, x: x, swaps: swaps})
+	return x, true
+}
+
+type priorityQueueUndoPopAction[T any] struct {
+	q     *PriorityQueue[T]
+	x     T
+	swaps []int
+}
+
+func (r *priorityQueueUndoPopAction[T]) Undo() {
+	r.q.heap.Push(r.x)
+	r.q.heap.unswap(r.swaps)
+}
+
+func (r *priorityQueueUndoPopAction[T]) Redo() {
+	_, r.swaps = r.q.heap.pop()
+}
+
+func (r *priorityQueueUndoPopAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.swaps)*sizeOf[int]()
+}
+
+// Range calls f sequentially for each element in heap order, which is not
+// sorted. If f returns false, Range stops the iteration.
+func (q *PriorityQueue[T]) Range(f func(T) bool) bool {
+	for _, x := range q.heap.data {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all elements from the PriorityQueue. The backing array is
+// handed over to the recorded action instead of being copied, and the queue
+// starts again without one.
+func (q *PriorityQueue[T]) Clear() {
+	q.recorder.PushAction(&priorityQueueUndoClearAction[T]{q: q, oldData: q.heap.data})
+	q.heap.data = nil
+}
+
+type priorityQueueUndoClearAction[T any] struct {
+	q       *PriorityQueue[T]
+	oldData []T
+}
+
+func (r *priorityQueueUndoClearAction[T]) Undo() {
+	r.q.heap.data = r.oldData
+}
+
+func (r *priorityQueueUndoClearAction[T]) Redo() {
+	r.oldData = r.q.heap.data
+	r.q.heap.data = nil
+}
+
+func (r *priorityQueueUndoClearAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.oldData)*sizeOf[T]()
+}
@@ -0,0 +1,83 @@
+package history_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func lessInt(a, b int) bool { return a < b }
+
+func TestPriorityQueue_Order(t *testing.T) {
+	q := history.NewPriorityQueue(&history.BaseRecorder{}, lessInt, 0)
+	if _, ok := q.Pop(); ok {
+		t.Errorf("Expected Pop on empty queue to fail")
+	}
+	for _, x := range []int{5, 1, 4, 2, 3} {
+		q.Push(x)
+	}
+	if x, ok := q.Peek(); !ok || x != 1 {
+		t.Errorf("Peek() = %d, %v", x, ok)
+	}
+	for want := 1; want <= 5; want++ {
+		if x, ok := q.Pop(); !ok || x != want {
+			t.Errorf("Pop() = %d, %v, want %d", x, ok, want)
+		}
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected empty queue")
+	}
+}
+
+func TestPriorityQueue_UndoRedo(t *testing.T) {
+	s := history.NewUndoStack()
+	q := history.NewPriorityQueue(s, lessInt, 0)
+	r := rand.New(rand.NewSource(1))
+	var states []string
+	for i := 0; i < 200; i++ {
+		states = append(states, q.String())
+		switch {
+		case i == 150:
+			q.Clear()
+		case r.Intn(3) == 0:
+			if _, ok := q.Pop(); !ok {
+				states = states[:len(states)-1]
+			}
+		default:
+			q.Push(r.Intn(20))
+		}
+	}
+	final := q.String()
+	for i := len(states) - 1; i >= 0; i-- {
+		s.Undo()
+		if got := q.String(); got != states[i] {
+			t.Fatalf("Undo step %d: expected exact layout %s, got %s", len(states)-i, states[i], got)
+		}
+	}
+	for s.Redo() {
+	}
+	if q.String() != final {
+		t.Errorf("Expected %s after redo, got %s", final, q)
+	}
+}
+
+func TestPriorityQueue_Clone(t *testing.T) {
+	recorder := &history.BaseRecorder{}
+	q := history.NewPriorityQueue(recorder, lessInt, 0)
+	q.Push(2)
+	q.Push(1)
+	clone := q.Clone(&history.BaseRecorder{})
+	recorder.UndoAll()
+	if clone.Len() != 2 || q.Len() != 0 {
+		t.Errorf("Expected independent clone, got %v and %v", clone, q)
+	}
+	var n int
+	clone.Range(func(int) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Expected Range to stop, got %d calls", n)
+	}
+}
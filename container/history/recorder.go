@@ -1,5 +1,7 @@
 package history
 
+import "unsafe"
+
 // Recorder defines the interface for adding undo actions and performing undo operations.
 type Recorder interface {
 	// PushAction adds a new undo action to the recorder.
@@ -20,6 +22,44 @@ type Action interface {
 	Undo()
 }
 
+// RedoableAction is an Action which can reapply its changes after being undone.
+// All actions recorded by the containers of this package are redoable.
+type RedoableAction interface {
+	Action
+
+	// Redo reapplies the changes reverted by the last call to Undo.
+	Redo()
+}
+
+// SizedAction is an Action which reports the approximate number of bytes it
+// retains, e.g. the elements saved to undo clearing a container. It's used by
+// the memory budget of an UndoStack. The size is shallow: memory referenced by
+// the saved values, such as the contents of strings, is not counted.
+// All actions recorded by the containers of this package are sized.
+type SizedAction interface {
+	Action
+
+	// Size returns the approximate number of bytes retained by the action.
+	Size() int
+}
+
+// defaultActionSize is the size assumed for actions which don't implement SizedAction.
+const defaultActionSize = 64
+
+// actionSize returns the approximate number of bytes retained by a.
+func actionSize(a Action) int {
+	if s, ok := a.(SizedAction); ok {
+		return s.Size()
+	}
+	return defaultActionSize
+}
+
+// sizeOf returns the size of a value of type T.
+func sizeOf[T any]() int {
+	var x T
+	return int(unsafe.Sizeof(x))
+}
+
 // BaseRecorder implements the Recorder interface using a slice of Actions.
 type BaseRecorder struct {
 	actions []Action
@@ -71,9 +111,21 @@ func ValueUndoAction[T any](ptr *T, old T) Action {
 type valueUndoAction[T any] struct {
 	ptr *T
 	old T
+	new T
 }
 
 // Undo restores the original value.
 func (a *valueUndoAction[T]) Undo() {
+	a.new = *a.ptr
 	*a.ptr = a.old
 }
+
+// Redo restores the value replaced by Undo.
+func (a *valueUndoAction[T]) Redo() {
+	*a.ptr = a.new
+}
+
+// Size returns the size of the action.
+func (a *valueUndoAction[T]) Size() int {
+	return int(unsafe.Sizeof(*a))
+}
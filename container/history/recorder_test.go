@@ -130,3 +130,57 @@ func BenchmarkDefaultRecorder_Undo(b *testing.B) {
 		recorder.Undo()
 	}
 }
+
+func TestClearAllocs(t *testing.T) {
+	const n = 1000
+	recorder := &history.BaseRecorder{}
+	slice := history.NewSlice[int](recorder, 0, n)
+	queue := history.NewPriorityQueue(recorder, func(a, b int) bool { return a < b }, n)
+	deque := history.NewDeque[int](recorder, n)
+	m := history.NewMap[int, int](recorder, n)
+	set := history.NewSet[int](recorder, n)
+	om := history.NewOrderedMap[int, int](recorder, n)
+	for i := 0; i < n; i++ {
+		slice.Append(i)
+		queue.Push(i)
+		deque.PushBack(i)
+		m.Set(i, i)
+		set.Add(i)
+		om.Set(i, i)
+	}
+	recorder.UndoAll()
+	for i := 0; i < n; i++ {
+		slice.Append(i)
+		queue.Push(i)
+		deque.PushBack(i)
+		m.Set(i, i)
+		set.Add(i)
+		om.Set(i, i)
+	}
+
+	// Clear allocates its action, plus an empty map for the map containers,
+	// whatever the number of elements
+	for _, tt := range []struct {
+		name  string
+		clear func()
+		max   float64
+	}{
+		{"Slice", slice.Clear, 1},
+		{"PriorityQueue", queue.Clear, 1},
+		{"Deque", deque.Clear, 1},
+		{"Map", m.Clear, 2},
+		{"Set", set.Clear, 2},
+		{"OrderedMap", om.Clear, 2},
+	} {
+		allocs := testing.AllocsPerRun(100, func() {
+			tt.clear()
+			recorder.Undo()
+		})
+		if allocs > tt.max {
+			t.Errorf("%s: expected at most %v allocations for Clear and Undo, got %v", tt.name, tt.max, allocs)
+		}
+	}
+	if slice.Len() != n || queue.Len() != n || deque.Len() != n || m.Len() != n || set.Len() != n || om.Len() != n {
+		t.Errorf("Expected %d elements after undoing Clear", n)
+	}
+}
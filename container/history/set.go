@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"maps"
 	"strings"
+	"unsafe"
 )
 
 // Set is a generic set with elements of type K that supports undo operations.
 type Set[K comparable] struct {
-	recorder Recorder
-	data     map[K]struct{}
+	recorder  Recorder
+	data      map[K]struct{}
+	observers observers[Change[K, bool]]
 }
 
 // NewSet creates a new Set with the given recorder and initial size.
@@ -45,6 +47,16 @@ func (s *Set[K]) Clone(recorder Recorder) *Set[K] {
 	}
 }
 
+// Snapshot returns the elements of the Set in unspecified order, e.g. to be
+// stored with a journal and restored by RestoreSet.
+func (s *Set[K]) Snapshot() []K {
+	elements := make([]K, 0, len(s.data))
+	for k := range s.data {
+		elements = append(elements, k)
+	}
+	return elements
+}
+
 // Len returns the number of elements in the Set.
 func (s *Set[K]) Len() int {
 	return len(s.data)
@@ -63,6 +75,7 @@ func (s *Set[K]) Add(k K) (added bool) {
 	if !found {
 		s.data[k] = struct{}{}
 		s.recorder.PushAction(&setUndoAddAction[K]{s: s, k: k})
+		s.observers.notify(Change[K, bool]{Kind: ChangeInsert, Key: k, New: true})
 		added = true
 	}
 	return
@@ -75,6 +88,16 @@ type setUndoAddAction[K comparable] struct {
 
 func (r *setUndoAddAction[K]) Undo() {
 	delete(r.s.data, r.k)
+	r.s.observers.notify(Change[K, bool]{Kind: ChangeRemove, Key: r.k, Old: true})
+}
+
+func (r *setUndoAddAction[K]) Redo() {
+	r.s.data[r.k] = struct{}{}
+	r.s.observers.notify(Change[K, bool]{Kind: ChangeInsert, Key: r.k, New: true})
+}
+
+func (r *setUndoAddAction[K]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // Remove removes an element from the Set.
@@ -84,6 +107,7 @@ func (s *Set[K]) Remove(k K) (removed bool) {
 	if removed {
 		s.recorder.PushAction(&setUndoRemoveAction[K]{s: s, k: k})
 		delete(s.data, k)
+		s.observers.notify(Change[K, bool]{Kind: ChangeRemove, Key: k, Old: true})
 	}
 	return
 }
@@ -95,6 +119,16 @@ type setUndoRemoveAction[K comparable] struct {
 
 func (r *setUndoRemoveAction[K]) Undo() {
 	r.s.data[r.k] = struct{}{}
+	r.s.observers.notify(Change[K, bool]{Kind: ChangeInsert, Key: r.k, New: true})
+}
+
+func (r *setUndoRemoveAction[K]) Redo() {
+	delete(r.s.data, r.k)
+	r.s.observers.notify(Change[K, bool]{Kind: ChangeRemove, Key: r.k, Old: true})
+}
+
+func (r *setUndoRemoveAction[K]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // Range calls f sequentially for each element in the Set.
@@ -108,10 +142,13 @@ func (s *Set[K]) Range(f func(K) bool) bool {
 	return true
 }
 
-// Clear removes all elements from the Set.
+// Clear removes all elements from the Set. The elements are handed over to
+// the recorded action instead of being copied, and the Set starts again with
+// an empty map, so Clear takes constant time.
 func (s *Set[K]) Clear() {
-	s.recorder.PushAction(&setUndoClearAction[K]{s: s, values: maps.Clone(s.data)})
-	clear(s.data)
+	s.recorder.PushAction(&setUndoClearAction[K]{s: s, values: s.data})
+	s.data = make(map[K]struct{})
+	s.observers.notify(Change[K, bool]{Kind: ChangeClear})
 }
 
 type setUndoClearAction[K comparable] struct {
@@ -121,4 +158,42 @@ type setUndoClearAction[K comparable] struct {
 
 func (r *setUndoClearAction[K]) Undo() {
 	r.s.data = r.values
+	for k := range r.values {
+		r.s.observers.notify(Change[K, bool]{Kind: ChangeInsert, Key: k, New: true})
+	}
+}
+
+func (r *setUndoClearAction[K]) Redo() {
+	r.values = r.s.data
+	r.s.data = make(map[K]struct{})
+	r.s.observers.notify(Change[K, bool]{Kind: ChangeClear})
+}
+
+func (r *setUndoClearAction[K]) Size() int {
+	return int(unsafe.Sizeof(*r)) + len(r.values)*sizeOf[K]()
+}
+
+// Subscribe implements the Observable interface. The changes are reported as
+// described by Change.
+func (s *Set[K]) Subscribe(fn func(Change[K, bool])) (unsubscribe func()) {
+	return s.observers.subscribe(fn)
+}
+
+// Apply applies the changes of the patch to the Set, recording them as any
+// other mutation. It returns an error if the patch contains a change which
+// can't be applied to a Set.
+func (s *Set[K]) Apply(patch Patch[K, bool]) error {
+	for i, c := range patch {
+		switch c.Kind {
+		case ChangeInsert:
+			s.Add(c.Key)
+		case ChangeRemove:
+			s.Remove(c.Key)
+		case ChangeClear:
+			s.Clear()
+		default:
+			return fmt.Errorf("history: change %d: unexpected %v change for set", i, c.Kind)
+		}
+	}
+	return nil
 }
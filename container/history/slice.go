@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"unsafe"
 )
 
 // Slice contains a slice data with recorder
 type Slice[T any] struct {
-	recorder Recorder
-	data     []T
+	recorder  Recorder
+	data      []T
+	observers observers[Change[int, T]]
 }
 
 // NewSlice creates a new slice with the given recorder, length, and capacity.
@@ -43,6 +45,12 @@ func (s *Slice[T]) Clone(recorder Recorder) *Slice[T] {
 	}
 }
 
+// Snapshot returns a copy of the elements of the Slice, e.g. to be stored
+// with a journal and restored by RestoreSlice.
+func (s *Slice[T]) Snapshot() []T {
+	return slices.Clone(s.data)
+}
+
 // Clip reduces the slice's capacity to match its length.
 func (s *Slice[T]) Clip() {
 	s.data = slices.Clip(s.data)
@@ -85,18 +93,32 @@ func (s *Slice[T]) Get(i int) T {
 
 // Set sets the i-th element of the slice to x.
 func (s *Slice[T]) Set(i int, x T) {
-	s.recorder.PushAction(&sliceUndoSetAction[T]{s: s, i: i, oldValue: s.data[i]})
+	old := s.data[i]
+	s.recorder.PushAction(&sliceUndoSetAction[T]{s: s, i: i, oldValue: old})
 	s.data[i] = x
+	s.observers.notify(Change[int, T]{Kind: ChangeSet, Key: i, Old: old, New: x, Replaced: true})
 }
 
 type sliceUndoSetAction[T any] struct {
 	s        *Slice[T]
 	i        int
 	oldValue T
+	newValue T
 }
 
 func (r *sliceUndoSetAction[T]) Undo() {
+	r.newValue = r.s.data[r.i]
 	r.s.data[r.i] = r.oldValue
+	r.s.observers.notify(Change[int, T]{Kind: ChangeSet, Key: r.i, Old: r.newValue, New: r.oldValue, Replaced: true})
+}
+
+func (r *sliceUndoSetAction[T]) Redo() {
+	r.s.data[r.i] = r.newValue
+	r.s.observers.notify(Change[int, T]{Kind: ChangeSet, Key: r.i, Old: r.oldValue, New: r.newValue, Replaced: true})
+}
+
+func (r *sliceUndoSetAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // RemoveAt removes the i-th element from the slice and returns it.
@@ -104,6 +126,7 @@ func (s *Slice[T]) RemoveAt(i int) T {
 	removedValue := s.data[i]
 	s.recorder.PushAction(&sliceUndoRemoveAtAction[T]{s: s, i: i, value: removedValue})
 	s.data = slices.Delete(s.data, i, i+1)
+	s.observers.notify(Change[int, T]{Kind: ChangeRemove, Key: i, Old: removedValue})
 	return removedValue
 }
 
@@ -115,43 +138,85 @@ type sliceUndoRemoveAtAction[T any] struct {
 
 func (r *sliceUndoRemoveAtAction[T]) Undo() {
 	r.s.data = slices.Insert(r.s.data, r.i, r.value)
+	r.s.observers.notify(Change[int, T]{Kind: ChangeInsert, Key: r.i, New: r.value})
+}
+
+func (r *sliceUndoRemoveAtAction[T]) Redo() {
+	r.s.data = slices.Delete(r.s.data, r.i, r.i+1)
+	r.s.observers.notify(Change[int, T]{Kind: ChangeRemove, Key: r.i, Old: r.value})
+}
+
+func (r *sliceUndoRemoveAtAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // Append appends elements to the slice.
 func (s *Slice[T]) Append(elements ...T) {
 	s.recorder.PushAction(&sliceUndoAppendAction[T]{s: s, n: len(elements)})
+	i := len(s.data)
 	s.data = append(s.data, elements...)
+	s.notifyInserted(i, elements)
 }
 
 type sliceUndoAppendAction[T any] struct {
-	s *Slice[T]
-	n int
+	s        *Slice[T]
+	n        int
+	elements []T
 }
 
 func (r *sliceUndoAppendAction[T]) Undo() {
-	r.s.data = r.s.data[:len(r.s.data)-r.n]
+	i := len(r.s.data) - r.n
+	r.elements = slices.Clone(r.s.data[i:])
+	r.s.data = r.s.data[:i]
+	r.s.notifyRemoved(i, r.elements)
+}
+
+func (r *sliceUndoAppendAction[T]) Redo() {
+	i := len(r.s.data)
+	r.s.data = append(r.s.data, r.elements...)
+	r.s.notifyInserted(i, r.elements)
+	r.elements = nil
+}
+
+func (r *sliceUndoAppendAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.elements)*sizeOf[T]()
 }
 
 // Insert inserts elements at i-th position of the slice.
 func (s *Slice[T]) Insert(i int, elements ...T) {
 	s.recorder.PushAction(&sliceUndoInsertAction[T]{s: s, i: i, n: len(elements)})
 	s.data = slices.Insert(s.data, i, elements...)
+	s.notifyInserted(i, elements)
 }
 
 type sliceUndoInsertAction[T any] struct {
-	s *Slice[T]
-	i int
-	n int
+	s        *Slice[T]
+	i        int
+	n        int
+	elements []T
 }
 
 func (r *sliceUndoInsertAction[T]) Undo() {
+	r.elements = slices.Clone(r.s.data[r.i : r.i+r.n])
 	r.s.data = slices.Delete(r.s.data, r.i, r.i+r.n)
+	r.s.notifyRemoved(r.i, r.elements)
+}
+
+func (r *sliceUndoInsertAction[T]) Redo() {
+	r.s.data = slices.Insert(r.s.data, r.i, r.elements...)
+	r.s.notifyInserted(r.i, r.elements)
+	r.elements = nil
+}
+
+func (r *sliceUndoInsertAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.elements)*sizeOf[T]()
 }
 
 // Reverse reverses the order of elements in the slice.
 func (s *Slice[T]) Reverse() {
 	s.recorder.PushAction(&sliceUndoReverseAction[T]{s: s})
 	slices.Reverse(s.data)
+	s.observers.notify(Change[int, T]{Kind: ChangeReverse})
 }
 
 type sliceUndoReverseAction[T any] struct {
@@ -160,6 +225,16 @@ type sliceUndoReverseAction[T any] struct {
 
 func (r *sliceUndoReverseAction[T]) Undo() {
 	slices.Reverse(r.s.data)
+	r.s.observers.notify(Change[int, T]{Kind: ChangeReverse})
+}
+
+func (r *sliceUndoReverseAction[T]) Redo() {
+	slices.Reverse(r.s.data)
+	r.s.observers.notify(Change[int, T]{Kind: ChangeReverse})
+}
+
+func (r *sliceUndoReverseAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r))
 }
 
 // RemoveFirst removes the first occurrence of the specified value from the slice.
@@ -174,11 +249,14 @@ func (s *Slice[T]) RemoveFirst(value T, eq func(a, b T) bool) bool {
 	return false
 }
 
-// Clear removes all elements from the slice.
+// Clear removes all elements from the slice. The backing array is handed over
+// to the recorded action instead of being copied, and the slice starts again
+// without one, so Clear takes constant time and allocates nothing but the
+// action.
 func (s *Slice[T]) Clear() {
-	oldData := slices.Clone(s.data)
-	s.recorder.PushAction(&sliceUndoClearAction[T]{s: s, oldData: oldData})
-	s.data = s.data[:0]
+	s.recorder.PushAction(&sliceUndoClearAction[T]{s: s, oldData: s.data})
+	s.data = nil
+	s.observers.notify(Change[int, T]{Kind: ChangeClear})
 }
 
 type sliceUndoClearAction[T any] struct {
@@ -187,5 +265,69 @@ type sliceUndoClearAction[T any] struct {
 }
 
 func (r *sliceUndoClearAction[T]) Undo() {
-	r.s.data = slices.Clone(r.oldData)
+	r.s.data = r.oldData
+	r.s.notifyInserted(0, r.oldData)
+}
+
+func (r *sliceUndoClearAction[T]) Redo() {
+	r.oldData = r.s.data
+	r.s.data = nil
+	r.s.observers.notify(Change[int, T]{Kind: ChangeClear})
+}
+
+func (r *sliceUndoClearAction[T]) Size() int {
+	return int(unsafe.Sizeof(*r)) + cap(r.oldData)*sizeOf[T]()
+}
+
+// notifyInserted reports the insertion of elements at index i.
+func (s *Slice[T]) notifyInserted(i int, elements []T) {
+	for j, x := range elements {
+		s.observers.notify(Change[int, T]{Kind: ChangeInsert, Key: i + j, New: x})
+	}
+}
+
+// notifyRemoved reports the removal of elements which were at index i. The
+// removals are reported from the last element so that each index is valid.
+func (s *Slice[T]) notifyRemoved(i int, elements []T) {
+	for j := len(elements) - 1; j >= 0; j-- {
+		s.observers.notify(Change[int, T]{Kind: ChangeRemove, Key: i + j, Old: elements[j]})
+	}
+}
+
+// Subscribe implements the Observable interface. The changes are reported as
+// described by Change.
+func (s *Slice[T]) Subscribe(fn func(Change[int, T])) (unsubscribe func()) {
+	return s.observers.subscribe(fn)
+}
+
+// Apply applies the changes of the patch to the Slice, recording them as any
+// other mutation. It returns an error and stops at the first change which
+// can't be applied, e.g. because its index is out of range.
+func (s *Slice[T]) Apply(patch Patch[int, T]) error {
+	for i, c := range patch {
+		n := len(s.data)
+		switch c.Kind {
+		case ChangeSet, ChangeRemove:
+			if c.Key < 0 || c.Key >= n {
+				return fmt.Errorf("history: change %d: index %d out of range [0, %d)", i, c.Key, n)
+			}
+			if c.Kind == ChangeSet {
+				s.Set(c.Key, c.New)
+			} else {
+				s.RemoveAt(c.Key)
+			}
+		case ChangeInsert:
+			if c.Key < 0 || c.Key > n {
+				return fmt.Errorf("history: change %d: index %d out of range [0, %d]", i, c.Key, n)
+			}
+			s.Insert(c.Key, c.New)
+		case ChangeClear:
+			s.Clear()
+		case ChangeReverse:
+			s.Reverse()
+		default:
+			return fmt.Errorf("history: change %d: unexpected %v change for slice", i, c.Kind)
+		}
+	}
+	return nil
 }
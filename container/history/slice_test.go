@@ -320,3 +320,35 @@ func TestSliceClip(t *testing.T) {
 		t.Errorf("Expected capacity to remain 5 after undo, got %d", s.Cap())
 	}
 }
+
+func BenchmarkSlice_ClearUndo(b *testing.B) {
+	recorder := &history.BaseRecorder{}
+	s := history.NewSlice[int](recorder, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		s.Append(i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Clear()
+		recorder.Undo()
+	}
+}
+
+func TestSlice_ClearThenAppend(t *testing.T) {
+	recorder := &history.BaseRecorder{}
+	s := history.NewSlice[int](recorder, 0, 8)
+	s.Append(1, 2, 3)
+	s.Clear()
+	s.Append(4, 5, 6, 7, 8, 9)
+	s.Set(0, 10)
+	if s.String() != "[10,5,6,7,8,9]" {
+		t.Fatalf("Unexpected slice %v", s)
+	}
+	recorder.Undo()
+	recorder.Undo()
+	recorder.Undo()
+	if s.String() != "[1,2,3]" {
+		t.Errorf("Expected cleared elements to be intact, got %v", s)
+	}
+}
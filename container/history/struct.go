@@ -0,0 +1,110 @@
+package history
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// Assign sets *ptr to v and records an action which restores the old value.
+// It makes assignments to fields of arbitrary objects undoable:
+//
+//	history.Assign(recorder, &unit.HP, unit.HP-damage)
+//	history.Assign(recorder, &unit.Pos, target)
+func Assign[T any](recorder Recorder, ptr *T, v T) {
+	recorder.PushAction(ValueUndoAction(ptr, *ptr))
+	*ptr = v
+}
+
+// Struct tracks the assignments to the fields of a struct of type S.
+//
+// Only the struct value itself is tracked: the contents referenced by fields
+// of pointer, slice or map types are not restored by undo, use the containers
+// of this package for them.
+type Struct[S any] struct {
+	recorder Recorder
+	ptr      *S
+}
+
+// NewStruct creates a new Struct which tracks the struct pointed to by ptr.
+func NewStruct[S any](recorder Recorder, ptr *S) *Struct[S] {
+	if t := reflect.TypeOf(ptr).Elem(); t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("history: NewStruct called with non-struct type %v", t))
+	}
+	return &Struct[S]{recorder: recorder, ptr: ptr}
+}
+
+// Get returns the tracked struct. Assignments made through the returned
+// pointer are not recorded.
+func (s *Struct[S]) Get() *S {
+	return s.ptr
+}
+
+// Update calls fn with the tracked struct and records an action which restores
+// the struct as it was before the call.
+//
+//	unit.Update(func(u *Unit) {
+//		u.HP -= damage
+//		u.State = Stunned
+//	})
+func (s *Struct[S]) Update(fn func(*S)) {
+	s.recorder.PushAction(ValueUndoAction(s.ptr, *s.ptr))
+	fn(s.ptr)
+}
+
+// SetField sets the field of the tracked struct with the given name to value
+// and records an action which restores the old value. Fields of nested
+// structs are named by dot-separated paths, e.g. "Pos.X". It returns an error
+// if the field doesn't exist, is not exported or value is not assignable to it.
+func (s *Struct[S]) SetField(name string, value any) error {
+	field := reflect.ValueOf(s.ptr).Elem()
+	for _, part := range strings.Split(name, ".") {
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("history: field %q: %v is not a struct", name, field.Type())
+		}
+		f, ok := field.Type().FieldByName(part)
+		if !ok {
+			return fmt.Errorf("history: field %q not found in %v", name, reflect.TypeOf(s.ptr).Elem())
+		}
+		if !f.IsExported() {
+			return fmt.Errorf("history: field %q is not exported", name)
+		}
+		var err error
+		if field, err = field.FieldByIndexErr(f.Index); err != nil {
+			return fmt.Errorf("history: field %q: %w", name, err)
+		}
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		v = reflect.Zero(field.Type())
+	}
+	if !v.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf("history: cannot assign %v to field %q of type %v", v.Type(), name, field.Type())
+	}
+	old := reflect.New(field.Type()).Elem()
+	old.Set(field)
+	s.recorder.PushAction(&fieldUndoAction{field: field, old: old})
+	field.Set(v)
+	return nil
+}
+
+type fieldUndoAction struct {
+	field reflect.Value
+	old   reflect.Value
+	new   reflect.Value
+}
+
+func (a *fieldUndoAction) Undo() {
+	a.new = reflect.New(a.field.Type()).Elem()
+	a.new.Set(a.field)
+	a.field.Set(a.old)
+}
+
+func (a *fieldUndoAction) Redo() {
+	a.field.Set(a.new)
+}
+
+func (a *fieldUndoAction) Size() int {
+	return int(unsafe.Sizeof(*a)) + 2*int(a.field.Type().Size())
+}
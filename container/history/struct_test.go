@@ -0,0 +1,102 @@
+package history_test
+
+import (
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+type point struct{ X, Y int }
+
+type unit struct {
+	Name  string
+	HP    int
+	Pos   point
+	Tags  []string
+	level int
+}
+
+func TestAssign(t *testing.T) {
+	s := history.NewUndoStack()
+	u := unit{Name: "knight", HP: 10}
+	history.Assign(s, &u.HP, 7)
+	history.Assign(s, &u.Pos, point{1, 2})
+	s.Undo()
+	if u.Pos != (point{}) || u.HP != 7 {
+		t.Errorf("Unexpected unit after undo %+v", u)
+	}
+	s.Undo()
+	if u.HP != 10 {
+		t.Errorf("Expected HP 10, got %d", u.HP)
+	}
+	s.Redo()
+	if u.HP != 7 {
+		t.Errorf("Expected HP 7 after redo, got %d", u.HP)
+	}
+}
+
+func TestStruct_Update(t *testing.T) {
+	s := history.NewUndoStack()
+	u := &unit{Name: "archer", HP: 5}
+	tracked := history.NewStruct(s, u)
+	tracked.Update(func(u *unit) {
+		u.HP = 3
+		u.Pos.X = 4
+		u.level = 2
+	})
+	if tracked.Get() != u || u.HP != 3 || u.level != 2 {
+		t.Fatalf("Unexpected unit %+v", u)
+	}
+	s.Undo()
+	if u.HP != 5 || u.Pos.X != 0 || u.level != 0 {
+		t.Errorf("Expected update to be undone, got %+v", u)
+	}
+	s.Redo()
+	if u.HP != 3 || u.Pos.X != 4 || u.level != 2 {
+		t.Errorf("Expected update to be redone, got %+v", u)
+	}
+}
+
+func TestStruct_SetField(t *testing.T) {
+	s := history.NewUndoStack()
+	u := &unit{Name: "mage", HP: 8, Tags: []string{"a"}}
+	tracked := history.NewStruct(s, u)
+
+	if err := tracked.SetField("Pos.Y", 6); err != nil {
+		t.Fatalf("SetField failed: %v", err)
+	}
+	if err := tracked.SetField("Tags", nil); err != nil {
+		t.Fatalf("SetField failed: %v", err)
+	}
+	if u.Pos.Y != 6 || u.Tags != nil {
+		t.Fatalf("Unexpected unit %+v", u)
+	}
+	s.UndoAll()
+	if u.Pos.Y != 0 || len(u.Tags) != 1 {
+		t.Errorf("Expected fields to be restored, got %+v", u)
+	}
+
+	for name, value := range map[string]any{
+		"Missing": 1,
+		"level":   1,
+		"HP":      "full",
+		"HP.X":    1,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := tracked.SetField(name, value); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+	if s.Len() != 0 {
+		t.Errorf("Expected failed assignments not to be recorded")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic for a non-struct type")
+		}
+	}()
+	var n int
+	history.NewStruct(s, &n)
+}
@@ -0,0 +1,299 @@
+package history
+
+import "sync"
+
+// LockedRecorder is a Recorder which is safe for concurrent use. Its
+// read-write mutex also guards the containers created by NewSyncMap,
+// NewSyncSet and NewSyncSlice with it, so that undoing actions, which mutates
+// the containers, is serialized with their readers and writers.
+type LockedRecorder struct {
+	mu       sync.RWMutex
+	recorder Recorder
+}
+
+// NewLockedRecorder creates a new LockedRecorder which records actions with recorder.
+func NewLockedRecorder(recorder Recorder) *LockedRecorder {
+	return &LockedRecorder{recorder: recorder}
+}
+
+// PushAction implements the Recorder interface.
+func (r *LockedRecorder) PushAction(a Action) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorder.PushAction(a)
+}
+
+// UndoAll implements the Recorder interface.
+func (r *LockedRecorder) UndoAll() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recorder.UndoAll()
+}
+
+// Undo implements the Recorder interface.
+func (r *LockedRecorder) Undo() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recorder.Undo()
+}
+
+// Do calls fn with the underlying recorder while holding the write lock, e.g.
+// to redo or to run a transaction of an UndoStack. The containers guarded by r
+// must not be accessed through their synchronized methods within fn, which
+// would deadlock, use the containers passed to their Update method instead.
+func (r *LockedRecorder) Do(fn func(recorder Recorder)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.recorder)
+}
+
+// SyncMap is a Map which is safe for concurrent use. It is guarded by the
+// read-write mutex of its LockedRecorder.
+type SyncMap[K comparable, V any] struct {
+	r *LockedRecorder
+	m *Map[K, V]
+}
+
+// NewSyncMap creates a new SyncMap with the given recorder and initial size.
+func NewSyncMap[K comparable, V any](recorder *LockedRecorder, size int) *SyncMap[K, V] {
+	return &SyncMap[K, V]{r: recorder, m: NewMap[K, V](recorder.recorder, size)}
+}
+
+// String returns a string representation of the SyncMap.
+func (m *SyncMap[K, V]) String() string {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	return m.m.String()
+}
+
+// Len returns the number of elements in the SyncMap.
+func (m *SyncMap[K, V]) Len() int {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	return m.m.Len()
+}
+
+// Contains checks if the SyncMap contains the given key.
+func (m *SyncMap[K, V]) Contains(k K) bool {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	return m.m.Contains(k)
+}
+
+// Get retrieves the value for a key in the SyncMap.
+func (m *SyncMap[K, V]) Get(k K) (V, bool) {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	return m.m.Get(k)
+}
+
+// Set adds or updates a key-value pair in the SyncMap.
+// It returns true if an existing entry was updated.
+func (m *SyncMap[K, V]) Set(k K, v V) bool {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	return m.m.Set(k, v)
+}
+
+// Remove removes a key-value pair from the SyncMap.
+// It returns the removed value and a boolean indicating if the key was present.
+func (m *SyncMap[K, V]) Remove(k K) (V, bool) {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	return m.m.Remove(k)
+}
+
+// Clear removes all elements from the SyncMap.
+func (m *SyncMap[K, V]) Clear() {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	m.m.Clear()
+}
+
+// Range calls f sequentially for each key and value while holding the read
+// lock. If f returns false, Range stops the iteration.
+func (m *SyncMap[K, V]) Range(f func(K, V) bool) bool {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	return m.m.Range(f)
+}
+
+// View calls fn with the underlying Map while holding the read lock.
+// fn must not modify the Map.
+func (m *SyncMap[K, V]) View(fn func(m *Map[K, V])) {
+	m.r.mu.RLock()
+	defer m.r.mu.RUnlock()
+	fn(m.m)
+}
+
+// Update calls fn with the underlying Map while holding the write lock, so
+// that the changes made by fn are atomic to other goroutines.
+func (m *SyncMap[K, V]) Update(fn func(m *Map[K, V])) {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	fn(m.m)
+}
+
+// SyncSet is a Set which is safe for concurrent use. It is guarded by the
+// read-write mutex of its LockedRecorder.
+type SyncSet[K comparable] struct {
+	r *LockedRecorder
+	s *Set[K]
+}
+
+// NewSyncSet creates a new SyncSet with the given recorder and initial size.
+func NewSyncSet[K comparable](recorder *LockedRecorder, size int) *SyncSet[K] {
+	return &SyncSet[K]{r: recorder, s: NewSet[K](recorder.recorder, size)}
+}
+
+// String returns a string representation of the SyncSet.
+func (s *SyncSet[K]) String() string {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.String()
+}
+
+// Len returns the number of elements in the SyncSet.
+func (s *SyncSet[K]) Len() int {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.Len()
+}
+
+// Contains checks if the SyncSet contains the given element.
+func (s *SyncSet[K]) Contains(k K) bool {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.Contains(k)
+}
+
+// Add adds an element to the SyncSet.
+// It returns true if the element was not already present.
+func (s *SyncSet[K]) Add(k K) bool {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	return s.s.Add(k)
+}
+
+// Remove removes an element from the SyncSet.
+// It returns true if the element was present.
+func (s *SyncSet[K]) Remove(k K) bool {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	return s.s.Remove(k)
+}
+
+// Clear removes all elements from the SyncSet.
+func (s *SyncSet[K]) Clear() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Clear()
+}
+
+// Range calls f sequentially for each element while holding the read lock.
+// If f returns false, Range stops the iteration.
+func (s *SyncSet[K]) Range(f func(K) bool) bool {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.Range(f)
+}
+
+// View calls fn with the underlying Set while holding the read lock.
+// fn must not modify the Set.
+func (s *SyncSet[K]) View(fn func(s *Set[K])) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	fn(s.s)
+}
+
+// Update calls fn with the underlying Set while holding the write lock, so
+// that the changes made by fn are atomic to other goroutines.
+func (s *SyncSet[K]) Update(fn func(s *Set[K])) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	fn(s.s)
+}
+
+// SyncSlice is a Slice which is safe for concurrent use. It is guarded by the
+// read-write mutex of its LockedRecorder.
+type SyncSlice[T any] struct {
+	r *LockedRecorder
+	s *Slice[T]
+}
+
+// NewSyncSlice creates a new SyncSlice with the given recorder, length, and capacity.
+func NewSyncSlice[T any](recorder *LockedRecorder, len, cap int) *SyncSlice[T] {
+	return &SyncSlice[T]{r: recorder, s: NewSlice[T](recorder.recorder, len, cap)}
+}
+
+// String returns a string representation of the SyncSlice.
+func (s *SyncSlice[T]) String() string {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.String()
+}
+
+// Len returns the length of the SyncSlice.
+func (s *SyncSlice[T]) Len() int {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.Len()
+}
+
+// Get returns the i-th element of the SyncSlice.
+func (s *SyncSlice[T]) Get(i int) T {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	return s.s.Get(i)
+}
+
+// Set sets the i-th element of the SyncSlice to x.
+func (s *SyncSlice[T]) Set(i int, x T) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Set(i, x)
+}
+
+// Append appends elements to the SyncSlice.
+func (s *SyncSlice[T]) Append(elements ...T) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Append(elements...)
+}
+
+// Insert inserts elements at i-th position of the SyncSlice.
+func (s *SyncSlice[T]) Insert(i int, elements ...T) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Insert(i, elements...)
+}
+
+// RemoveAt removes the i-th element from the SyncSlice and returns it.
+func (s *SyncSlice[T]) RemoveAt(i int) T {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	return s.s.RemoveAt(i)
+}
+
+// Clear removes all elements from the SyncSlice.
+func (s *SyncSlice[T]) Clear() {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.s.Clear()
+}
+
+// View calls fn with the underlying Slice while holding the read lock.
+// fn must not modify the Slice.
+func (s *SyncSlice[T]) View(fn func(s *Slice[T])) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	fn(s.s)
+}
+
+// Update calls fn with the underlying Slice while holding the write lock, so
+// that the changes made by fn are atomic to other goroutines.
+func (s *SyncSlice[T]) Update(fn func(s *Slice[T])) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	fn(s.s)
+}
@@ -0,0 +1,125 @@
+package history_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestSyncContainers(t *testing.T) {
+	recorder := history.NewLockedRecorder(history.NewUndoStack())
+	m := history.NewSyncMap[int, int](recorder, 0)
+	set := history.NewSyncSet[int](recorder, 0)
+	sl := history.NewSyncSlice[int](recorder, 0, 0)
+
+	const n = 100
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				k := i*n + j
+				m.Set(k, j)
+				set.Add(k)
+				sl.Append(k)
+				if j%10 == 0 {
+					// undoes the last action of any goroutine
+					recorder.Undo()
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				m.Get(j)
+				set.Contains(j)
+				m.Range(func(int, int) bool { return true })
+				sl.View(func(s *history.Slice[int]) {
+					if s.Len() > 0 {
+						s.Get(s.Len() - 1)
+					}
+				})
+				_ = m.String() + set.String() + sl.String()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total := m.Len() + set.Len() + sl.Len(); total != 3*4*n-40 {
+		t.Errorf("Expected %d elements, got %d", 3*4*n-40, total)
+	}
+	if n := recorder.UndoAll(); n == 0 || m.Len() != 0 || set.Len() != 0 || sl.Len() != 0 {
+		t.Errorf("Expected everything to be undone, got %d actions", n)
+	}
+}
+
+func TestSyncMap(t *testing.T) {
+	recorder := history.NewLockedRecorder(&history.BaseRecorder{})
+	m := history.NewSyncMap[string, int](recorder, 0)
+	if m.Set("a", 1) || !m.Set("a", 2) || !m.Contains("a") {
+		t.Errorf("Unexpected Set results")
+	}
+	if v, ok := m.Remove("a"); !ok || v != 2 {
+		t.Errorf("Remove() = %d, %v", v, ok)
+	}
+	m.Update(func(m *history.Map[string, int]) {
+		m.Set("x", 1)
+		m.Set("y", 2)
+	})
+	m.Clear()
+	recorder.Undo()
+	m.View(func(m *history.Map[string, int]) {
+		if m.Len() != 2 {
+			t.Errorf("Expected clear to be undone, got %v", m)
+		}
+	})
+}
+
+func TestSyncSet(t *testing.T) {
+	recorder := history.NewLockedRecorder(&history.BaseRecorder{})
+	s := history.NewSyncSet[string](recorder, 0)
+	if !s.Add("a") || s.Add("a") || !s.Remove("a") || s.Remove("a") {
+		t.Errorf("Unexpected Add and Remove results")
+	}
+	s.Update(func(s *history.Set[string]) {
+		s.Add("x")
+	})
+	s.Clear()
+	recorder.Undo()
+	var elems []string
+	s.Range(func(k string) bool {
+		elems = append(elems, k)
+		return true
+	})
+	s.View(func(s *history.Set[string]) {
+		if len(elems) != 1 || !s.Contains("x") {
+			t.Errorf("Expected clear to be undone, got %v", elems)
+		}
+	})
+}
+
+func TestSyncSlice(t *testing.T) {
+	stack := history.NewUndoStack()
+	recorder := history.NewLockedRecorder(stack)
+	s := history.NewSyncSlice[int](recorder, 0, 0)
+	s.Append(1, 3)
+	s.Insert(1, 2)
+	s.Set(0, 0)
+	if x := s.RemoveAt(2); x != 3 || s.String() != "[0,2]" {
+		t.Errorf("Unexpected slice %v after removing %d", s, x)
+	}
+	s.Update(func(s *history.Slice[int]) {
+		s.Append(4)
+	})
+	s.Clear()
+	recorder.Undo()
+	recorder.Do(func(history.Recorder) {
+		stack.Undo()
+		stack.Redo()
+	})
+	if s.Get(2) != 4 || s.Len() != 3 {
+		t.Errorf("Unexpected slice %v", s)
+	}
+}
@@ -0,0 +1,382 @@
+package history
+
+import "fmt"
+
+// UndoStack is a Recorder with redo, transactions and a bounded history.
+//
+// Each action pushed outside of a transaction forms an entry of its own, while
+// the actions pushed between Begin and Commit form a single entry which is
+// undone and redone atomically. Transactions can be nested: a nested
+// transaction acts as a savepoint, rolling it back reverts only the actions
+// pushed since the nested Begin, and committing it merges its actions into the
+// enclosing transaction.
+//
+// Entries can be redone only if all their actions implement RedoableAction.
+// Pushing a new entry discards the entries which could be redone.
+//
+// An UndoStack is not safe for concurrent use.
+type UndoStack struct {
+	maxLength int
+	budget    int
+	coalesce  bool
+	gen       uint64 // incremented at transaction boundaries
+	size      int    // size of the entries in undo and redo
+	undo      []undoEntry
+	redo      []undoEntry
+	txs       []undoEntry
+}
+
+// undoEntry is a group of actions which are undone and redone together.
+type undoEntry struct {
+	name    string
+	actions []Action
+	size    int
+}
+
+// computeSize computes the size of the entry and returns it.
+func (e *undoEntry) computeSize() int {
+	e.size = 0
+	for _, a := range e.actions {
+		e.size += actionSize(a)
+	}
+	return e.size
+}
+
+func (e *undoEntry) undo() {
+	for i := len(e.actions) - 1; i >= 0; i-- {
+		e.actions[i].Undo()
+	}
+}
+
+func (e *undoEntry) redo() {
+	for _, a := range e.actions {
+		a.(RedoableAction).Redo()
+	}
+}
+
+func (e *undoEntry) redoable() bool {
+	for _, a := range e.actions {
+		if _, ok := a.(RedoableAction); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// UndoStackOption represents an option for NewUndoStack.
+type UndoStackOption func(*UndoStack)
+
+// WithMaxLength limits the number of entries which can be undone. When the
+// limit is exceeded, the oldest entries are discarded. Zero means no limit.
+func WithMaxLength(n int) UndoStackOption {
+	return func(s *UndoStack) {
+		s.maxLength = n
+	}
+}
+
+// WithMemoryBudget limits the approximate number of bytes retained by the
+// entries which can be undone or redone, as reported by SizedAction. When the
+// budget is exceeded, the oldest entries are discarded, then the entries which
+// can be redone. The last entry is kept even if it exceeds the budget on its
+// own. Zero means no limit.
+func WithMemoryBudget(bytes int) UndoStackOption {
+	return func(s *UndoStack) {
+		s.budget = bytes
+	}
+}
+
+// WithCoalescing enables action coalescing within transactions: when a Map key
+// set in a transaction is set again, the redundant action is not recorded,
+// because undoing the first one restores the value the key had before the
+// transaction anyway. Removing the key or clearing the Map ends coalescing of
+// the key until the next set. A nested transaction doesn't coalesce actions
+// with its enclosing transaction.
+//
+// Coalescing reduces the memory used by transactions which repeatedly set the
+// same keys, e.g. updating positions every tick. The number of actions
+// reported by Rollback and UndoAll counts only the recorded actions.
+func WithCoalescing() UndoStackOption {
+	return func(s *UndoStack) {
+		s.coalesce = true
+	}
+}
+
+// coalescer is implemented by recorders which coalesce actions.
+//
+// Containers track the states overwritten by the actions they record while
+// coalescing, and skip recording actions which overwrite the same states
+// again. The tracked states are valid as long as the generation is unchanged.
+type coalescer interface {
+	// coalescing reports whether actions may currently be coalesced and
+	// returns the generation of the transaction in progress.
+	coalescing() (gen uint64, ok bool)
+}
+
+// NewUndoStack creates a new UndoStack with the given options.
+func NewUndoStack(options ...UndoStackOption) *UndoStack {
+	s := &UndoStack{}
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// PushAction implements the Recorder interface. The action is added to the
+// current transaction if any, otherwise it forms a new entry.
+func (s *UndoStack) PushAction(a Action) {
+	if n := len(s.txs); n > 0 {
+		s.txs[n-1].actions = append(s.txs[n-1].actions, a)
+		return
+	}
+	s.pushEntry(undoEntry{actions: []Action{a}})
+}
+
+func (s *UndoStack) coalescing() (uint64, bool) {
+	return s.gen, s.coalesce && len(s.txs) > 0
+}
+
+func (s *UndoStack) pushEntry(e undoEntry) {
+	s.clearRedo()
+	s.size += e.computeSize()
+	s.undo = append(s.undo, e)
+	if s.maxLength > 0 && len(s.undo) > s.maxLength {
+		s.evictUndo(len(s.undo) - s.maxLength)
+	}
+	s.checkBudget()
+}
+
+// evictUndo discards the n oldest entries which can be undone.
+func (s *UndoStack) evictUndo(n int) {
+	for _, e := range s.undo[:n] {
+		s.size -= e.size
+	}
+	m := copy(s.undo, s.undo[n:])
+	clear(s.undo[m:])
+	s.undo = s.undo[:m]
+}
+
+func (s *UndoStack) clearRedo() {
+	for _, e := range s.redo {
+		s.size -= e.size
+	}
+	clear(s.redo)
+	s.redo = s.redo[:0]
+}
+
+// checkBudget discards entries until the memory budget is met.
+func (s *UndoStack) checkBudget() {
+	if s.budget <= 0 {
+		return
+	}
+	for s.size > s.budget && len(s.undo)+len(s.redo) > 1 {
+		if len(s.undo) > 0 && (len(s.undo) > 1 || len(s.redo) == 0) {
+			s.evictUndo(1)
+		} else {
+			// discard the entry which would be redone last
+			s.size -= s.redo[0].size
+			m := copy(s.redo, s.redo[1:])
+			s.redo[m] = undoEntry{}
+			s.redo = s.redo[:m]
+		}
+	}
+}
+
+// Size returns the approximate number of bytes retained by the entries which
+// can be undone or redone. See SizedAction.
+func (s *UndoStack) Size() int {
+	return s.size
+}
+
+// Begin starts a named transaction. If a transaction is already in progress,
+// Begin starts a nested transaction which acts as a savepoint.
+func (s *UndoStack) Begin(name string) {
+	s.gen++
+	s.txs = append(s.txs, undoEntry{name: name})
+}
+
+// InTransaction reports whether a transaction is in progress.
+func (s *UndoStack) InTransaction() bool {
+	return len(s.txs) > 0
+}
+
+// Depth returns the number of nested transactions in progress.
+func (s *UndoStack) Depth() int {
+	return len(s.txs)
+}
+
+// Commit commits the innermost transaction. A nested transaction is merged into
+// the enclosing one, while an outermost transaction becomes an entry which can
+// be undone, unless it has no actions. It panics if no transaction is in progress.
+func (s *UndoStack) Commit() {
+	tx := s.popTx("Commit")
+	if n := len(s.txs); n > 0 {
+		s.txs[n-1].actions = append(s.txs[n-1].actions, tx.actions...)
+		return
+	}
+	if len(tx.actions) > 0 {
+		s.pushEntry(tx)
+	}
+}
+
+// Rollback undoes the actions of the innermost transaction and discards it.
+// It returns the number of actions undone. It panics if no transaction is in
+// progress.
+func (s *UndoStack) Rollback() int {
+	tx := s.popTx("Rollback")
+	tx.undo()
+	return len(tx.actions)
+}
+
+func (s *UndoStack) popTx(op string) undoEntry {
+	n := len(s.txs)
+	if n == 0 {
+		panic("history: " + op + " called without a transaction")
+	}
+	s.gen++
+	tx := s.txs[n-1]
+	s.txs[n-1] = undoEntry{}
+	s.txs = s.txs[:n-1]
+	return tx
+}
+
+// Transact runs fn in a named transaction. The transaction is committed if fn
+// returns nil, otherwise it's rolled back and the error is returned. If fn
+// panics, the transaction is rolled back and the panic is propagated.
+func (s *UndoStack) Transact(name string, fn func() error) (err error) {
+	s.Begin(name)
+	depth := len(s.txs)
+	defer func() {
+		if r := recover(); r != nil {
+			s.rollbackTo(depth)
+			panic(r)
+		}
+	}()
+	if err = fn(); err != nil {
+		s.rollbackTo(depth)
+		return err
+	}
+	if len(s.txs) != depth {
+		panic(fmt.Sprintf("history: unbalanced transactions within transaction %q", name))
+	}
+	s.Commit()
+	return nil
+}
+
+// rollbackTo rolls back transactions until fewer than depth are in progress.
+func (s *UndoStack) rollbackTo(depth int) {
+	for len(s.txs) >= depth {
+		s.Rollback()
+	}
+}
+
+// Undo implements the Recorder interface. It undoes the last entry and returns
+// true if there was one. It panics if a transaction is in progress.
+func (s *UndoStack) Undo() bool {
+	s.checkNoTx("Undo")
+	n := len(s.undo)
+	if n == 0 {
+		return false
+	}
+	e := s.undo[n-1]
+	s.undo[n-1] = undoEntry{}
+	s.undo = s.undo[:n-1]
+	s.size -= e.size
+	e.undo()
+	if e.redoable() {
+		// undoing may change the values retained by the actions
+		s.size += e.computeSize()
+		s.redo = append(s.redo, e)
+		s.checkBudget()
+	} else {
+		// entries after a non-redoable one can't be redone either
+		s.clearRedo()
+	}
+	return true
+}
+
+// Redo redoes the last undone entry and returns true if there was one.
+// It panics if a transaction is in progress.
+func (s *UndoStack) Redo() bool {
+	s.checkNoTx("Redo")
+	n := len(s.redo)
+	if n == 0 {
+		return false
+	}
+	e := s.redo[n-1]
+	s.redo[n-1] = undoEntry{}
+	s.redo = s.redo[:n-1]
+	s.size -= e.size
+	e.redo()
+	s.size += e.computeSize()
+	s.undo = append(s.undo, e)
+	s.checkBudget()
+	return true
+}
+
+// UndoAll implements the Recorder interface. It rolls back all transactions in
+// progress, undoes all entries and clears the stack. It returns the number of
+// actions undone.
+func (s *UndoStack) UndoAll() (n int) {
+	for len(s.txs) > 0 {
+		n += s.Rollback()
+	}
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		s.undo[i].undo()
+		n += len(s.undo[i].actions)
+	}
+	s.Clear()
+	return n
+}
+
+// Clear discards all entries without undoing them. Transactions in progress are
+// not affected.
+func (s *UndoStack) Clear() {
+	clear(s.undo)
+	s.undo = s.undo[:0]
+	clear(s.redo)
+	s.redo = s.redo[:0]
+	s.size = 0
+}
+
+func (s *UndoStack) checkNoTx(op string) {
+	if len(s.txs) > 0 {
+		panic(fmt.Sprintf("history: %s called within transaction %q", op, s.txs[len(s.txs)-1].name))
+	}
+}
+
+// Len returns the number of entries which can be undone.
+func (s *UndoStack) Len() int {
+	return len(s.undo)
+}
+
+// RedoLen returns the number of entries which can be redone.
+func (s *UndoStack) RedoLen() int {
+	return len(s.redo)
+}
+
+// CanUndo reports whether there is an entry to undo.
+func (s *UndoStack) CanUndo() bool {
+	return len(s.undo) > 0
+}
+
+// CanRedo reports whether there is an entry to redo.
+func (s *UndoStack) CanRedo() bool {
+	return len(s.redo) > 0
+}
+
+// UndoName returns the name of the entry which Undo would undo. Entries
+// created outside of transactions have empty names.
+func (s *UndoStack) UndoName() string {
+	if n := len(s.undo); n > 0 {
+		return s.undo[n-1].name
+	}
+	return ""
+}
+
+// RedoName returns the name of the entry which Redo would redo.
+func (s *UndoStack) RedoName() string {
+	if n := len(s.redo); n > 0 {
+		return s.redo[n-1].name
+	}
+	return ""
+}
@@ -0,0 +1,410 @@
+package history_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/container/history"
+)
+
+func TestUndoStack_UndoRedo(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+	set := history.NewSet[int](s, 0)
+	sl := history.NewSlice[int](s, 0, 0)
+
+	m.Set("a", 1)
+	m.Set("a", 2)
+	m.Remove("a")
+	m.Set("b", 3)
+	m.Clear()
+	set.Add(1)
+	set.Add(2)
+	set.Remove(1)
+	set.Clear()
+	sl.Append(1, 2, 3)
+	sl.Insert(1, 10, 11)
+	sl.Set(0, 5)
+	sl.RemoveAt(4)
+	sl.Reverse()
+	sl.Clear()
+	sl.Append(7)
+
+	snapshot := func() string {
+		var keys []string
+		m.Range(func(k string, v int) bool {
+			keys = append(keys, fmt.Sprintf("%s:%d", k, v))
+			return true
+		})
+		var elems []int
+		set.Range(func(k int) bool {
+			elems = append(elems, k)
+			return true
+		})
+		slices.Sort(keys)
+		slices.Sort(elems)
+		return fmt.Sprint(keys, elems, sl)
+	}
+	var states []string
+	for s.CanUndo() {
+		states = append(states, snapshot())
+		s.Undo()
+	}
+	if m.Len() != 0 || set.Len() != 0 || sl.Len() != 0 {
+		t.Fatalf("Expected empty containers after undoing everything, got %v %v %v", m, set, sl)
+	}
+	if s.RedoLen() != 16 {
+		t.Errorf("Expected 16 entries to redo, got %d", s.RedoLen())
+	}
+	for i := len(states) - 1; i >= 0; i-- {
+		if !s.Redo() {
+			t.Fatalf("Expected Redo to succeed")
+		}
+		if got := snapshot(); got != states[i] {
+			t.Errorf("Redo step %d: expected %s, got %s", len(states)-i, states[i], got)
+		}
+	}
+	if s.Redo() {
+		t.Errorf("Expected nothing to redo")
+	}
+}
+
+func TestUndoStack_NewActionClearsRedo(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	s.Undo()
+	if !s.CanRedo() {
+		t.Fatalf("Expected an entry to redo")
+	}
+	m.Set("c", 3)
+	if s.CanRedo() {
+		t.Errorf("Expected redo entries to be discarded by a new action")
+	}
+}
+
+type undoOnlyAction struct {
+	undone *bool
+}
+
+func (a undoOnlyAction) Undo() { *a.undone = true }
+
+func TestUndoStack_NonRedoableAction(t *testing.T) {
+	s := history.NewUndoStack()
+	value := 0
+	var undone bool
+	s.PushAction(undoOnlyAction{&undone})
+	s.PushAction(history.ValueUndoAction(&value, 0))
+	value = 1
+	s.Undo()
+	if !s.CanRedo() {
+		t.Fatalf("Expected value action to be redoable")
+	}
+	s.Undo()
+	if !undone {
+		t.Errorf("Expected action to be undone")
+	}
+	if s.CanRedo() {
+		t.Errorf("Expected entries after a non-redoable entry to be discarded")
+	}
+}
+
+func TestUndoStack_Transaction(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+	sl := history.NewSlice[string](s, 0, 0)
+
+	s.Begin("move")
+	m.Set("x", 1)
+	m.Set("y", 2)
+	sl.Append("moved")
+	if !s.InTransaction() || s.Len() != 0 {
+		t.Errorf("Expected actions to be held by the transaction")
+	}
+	s.Commit()
+	if s.Len() != 1 || s.UndoName() != "move" {
+		t.Fatalf("Expected one entry named move, got %d %q", s.Len(), s.UndoName())
+	}
+
+	s.Undo()
+	if m.Len() != 0 || sl.Len() != 0 {
+		t.Errorf("Expected transaction to be undone atomically, got %v %v", m, sl)
+	}
+	if s.RedoName() != "move" {
+		t.Errorf("Expected redo name move, got %q", s.RedoName())
+	}
+	s.Redo()
+	if v, _ := m.Get("y"); v != 2 || sl.Len() != 1 {
+		t.Errorf("Expected transaction to be redone atomically, got %v %v", m, sl)
+	}
+
+	s.Begin("nothing")
+	s.Commit()
+	if s.Len() != 1 {
+		t.Errorf("Expected empty transaction not to create an entry")
+	}
+}
+
+func TestUndoStack_Savepoints(t *testing.T) {
+	s := history.NewUndoStack()
+	sl := history.NewSlice[int](s, 0, 0)
+
+	s.Begin("outer")
+	sl.Append(1)
+	s.Begin("savepoint 1")
+	sl.Append(2)
+	s.Begin("savepoint 2")
+	sl.Append(3)
+	if s.Depth() != 3 {
+		t.Errorf("Expected depth 3, got %d", s.Depth())
+	}
+	if n := s.Rollback(); n != 1 || sl.String() != "[1,2]" {
+		t.Errorf("Expected rollback of savepoint 2 to undo 1 action, got %d: %v", n, sl)
+	}
+	s.Commit() // savepoint 1 merges into outer
+	sl.Append(4)
+	s.Commit()
+	if s.Len() != 1 || sl.String() != "[1,2,4]" {
+		t.Fatalf("Expected a single entry with [1,2,4], got %d: %v", s.Len(), sl)
+	}
+	s.Undo()
+	if sl.Len() != 0 {
+		t.Errorf("Expected the whole transaction to be undone, got %v", sl)
+	}
+
+	s.Begin("outer")
+	sl.Append(1)
+	s.Begin("inner")
+	sl.Append(2)
+	s.Commit()
+	if n := s.Rollback(); n != 2 || sl.Len() != 0 {
+		t.Errorf("Expected rollback of outer to undo committed nested actions, got %d: %v", n, sl)
+	}
+}
+
+func TestUndoStack_Transact(t *testing.T) {
+	s := history.NewUndoStack()
+	m := history.NewMap[string, int](s, 0)
+
+	if err := s.Transact("ok", func() error {
+		m.Set("a", 1)
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Len() != 1 {
+		t.Errorf("Expected committed transaction")
+	}
+
+	errFailed := errors.New("failed")
+	err := s.Transact("fail", func() error {
+		m.Set("b", 2)
+		s.Begin("unfinished")
+		m.Set("c", 3)
+		return errFailed
+	})
+	if err != errFailed || m.Len() != 1 || s.InTransaction() {
+		t.Errorf("Expected rolled back transaction, got %v %v", err, m)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic to be propagated")
+			}
+		}()
+		s.Transact("panic", func() error {
+			m.Set("d", 4)
+			panic("boom")
+		})
+	}()
+	if m.Contains("d") || s.InTransaction() {
+		t.Errorf("Expected panicking transaction to be rolled back")
+	}
+}
+
+func TestUndoStack_MaxLength(t *testing.T) {
+	s := history.NewUndoStack(history.WithMaxLength(3))
+	sl := history.NewSlice[int](s, 0, 0)
+	for i := 0; i < 5; i++ {
+		sl.Append(i)
+	}
+	if s.Len() != 3 {
+		t.Fatalf("Expected 3 entries, got %d", s.Len())
+	}
+	for s.Undo() {
+	}
+	if sl.String() != "[0,1]" {
+		t.Errorf("Expected oldest entries to be discarded, got %v", sl)
+	}
+}
+
+func TestUndoStack_UndoAll(t *testing.T) {
+	s := history.NewUndoStack()
+	sl := history.NewSlice[int](s, 0, 0)
+	sl.Append(1)
+	s.Begin("tx")
+	sl.Append(2, 3)
+	sl.Set(0, 9)
+	if n := s.UndoAll(); n != 3 {
+		t.Errorf("Expected 3 actions undone, got %d", n)
+	}
+	if sl.Len() != 0 || s.InTransaction() || s.CanUndo() || s.CanRedo() {
+		t.Errorf("Expected everything to be undone and cleared")
+	}
+}
+
+func TestUndoStack_Misuse(t *testing.T) {
+	for name, fn := range map[string]func(s *history.UndoStack){
+		"Commit without Begin":   func(s *history.UndoStack) { s.Commit() },
+		"Rollback without Begin": func(s *history.UndoStack) { s.Rollback() },
+		"Undo in transaction":    func(s *history.UndoStack) { s.Begin("tx"); s.Undo() },
+		"Redo in transaction":    func(s *history.UndoStack) { s.Begin("tx"); s.Redo() },
+		"Unbalanced Transact": func(s *history.UndoStack) {
+			s.Transact("tx", func() error { s.Begin("open"); return nil })
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic")
+				}
+			}()
+			fn(history.NewUndoStack())
+		})
+	}
+}
+
+func TestUndoStack_Interface(t *testing.T) {
+	var _ history.Recorder = history.NewUndoStack()
+}
+
+func TestUndoStack_Coalescing(t *testing.T) {
+	s := history.NewUndoStack(history.WithCoalescing())
+	m := history.NewMap[string, int](s, 0)
+	other := history.NewMap[string, int](s, 0)
+	m.Set("a", 1)
+
+	s.Begin("move")
+	for i := 0; i < 10; i++ {
+		m.Set("a", i+10)
+		m.Set("b", i+20)
+		other.Set("a", i)
+	}
+	if s.Rollback() != 3 {
+		t.Errorf("Expected repeated sets to be coalesced into 3 actions")
+	}
+	if v, _ := m.Get("a"); v != 1 || m.Contains("b") || other.Len() != 0 {
+		t.Fatalf("Unexpected state after rollback: %v %v", m, other)
+	}
+
+	s.Begin("remove and clear")
+	m.Set("a", 2)
+	m.Remove("a")
+	m.Set("a", 3) // recorded since the key was removed
+	m.Set("a", 4)
+	other.Set("x", 1)
+	other.Clear()
+	m.Set("a", 5) // recorded since a map was cleared
+	s.Commit()
+	if v, _ := m.Get("a"); v != 5 {
+		t.Fatalf("Expected 5, got %d", v)
+	}
+	s.Undo()
+	if v, _ := m.Get("a"); v != 1 || other.Len() != 0 {
+		t.Errorf("Expected transaction to be undone, got %v %v", m, other)
+	}
+	s.Redo()
+	if v, _ := m.Get("a"); v != 5 || other.Len() != 0 {
+		t.Errorf("Expected transaction to be redone, got %v %v", m, other)
+	}
+
+	s.Begin("outer")
+	m.Set("a", 6)
+	s.Begin("inner")
+	m.Set("a", 7) // not coalesced into the outer transaction
+	s.Rollback()
+	if v, _ := m.Get("a"); v != 6 {
+		t.Errorf("Expected rollback of the nested transaction to restore 6, got %d", v)
+	}
+	s.Rollback()
+	if v, _ := m.Get("a"); v != 5 {
+		t.Errorf("Expected 5, got %d", v)
+	}
+
+	// no coalescing outside of transactions
+	m.Set("a", 8)
+	m.Set("a", 9)
+	s.Undo()
+	if v, _ := m.Get("a"); v != 8 {
+		t.Errorf("Expected 8, got %d", v)
+	}
+}
+
+func TestUndoStack_MemoryBudget(t *testing.T) {
+	s := history.NewUndoStack(history.WithMemoryBudget(10000))
+	sl := history.NewSlice[int64](s, 0, 0)
+	for i := 0; i < 100; i++ {
+		sl.Append(make([]int64, 100)...) // at least 800 bytes once undone
+	}
+	if s.Len() != 100 || s.Size() > 10000 {
+		t.Fatalf("Expected appends to be small before undo, got %d entries of %d bytes", s.Len(), s.Size())
+	}
+	for s.Undo() {
+	}
+	if s.Size() > 10000 || s.RedoLen() > 12 || s.RedoLen() == 0 {
+		t.Errorf("Expected redo entries to be evicted, got %d entries of %d bytes", s.RedoLen(), s.Size())
+	}
+	for s.Redo() {
+	}
+	if s.Size() > 10000 {
+		t.Errorf("Expected budget to be met, got %d bytes", s.Size())
+	}
+
+	s.Clear()
+	if s.Size() != 0 {
+		t.Errorf("Expected size 0 after Clear, got %d", s.Size())
+	}
+	sl.Append(1, 2, 3)
+	big := make([]int64, 10000)
+	sl.Append(big...)
+	n := sl.Len()
+	sl.Clear() // exceeds the budget on its own
+	if s.Len() != 1 || !s.CanUndo() {
+		t.Errorf("Expected only the last entry to be kept, got %d", s.Len())
+	}
+	s.Undo()
+	if sl.Len() != n {
+		t.Errorf("Expected clear to be undone, got %d elements", sl.Len())
+	}
+}
+
+func BenchmarkUndoStack_Transaction(b *testing.B) {
+	for _, bm := range []struct {
+		name    string
+		options []history.UndoStackOption
+	}{
+		{"Default", nil},
+		{"Coalescing", []history.UndoStackOption{history.WithCoalescing()}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			s := history.NewUndoStack(bm.options...)
+			m := history.NewMap[int, int](s, 100)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// a transaction moving 100 units 10 times
+				s.Begin("tick")
+				for step := 0; step < 10; step++ {
+					for k := 0; k < 100; k++ {
+						m.Set(k, step)
+					}
+				}
+				s.Commit()
+			}
+		})
+	}
+}
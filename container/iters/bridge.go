@@ -0,0 +1,158 @@
+//go:build go1.23
+
+package iters
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"io"
+	"iter"
+
+	"github.com/gopherd/core/container/heap"
+)
+
+// FromChan returns an iterator that generates the values received from ch
+// until ch is closed or ctx is done.
+func FromChan[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// ToChan starts a goroutine which sends the elements of s to the returned
+// channel with the given buffer size, and closes the channel when s is
+// exhausted or ctx is done. Cancel ctx to stop the goroutine if the channel is
+// not drained.
+func ToChan[T any](ctx context.Context, s iter.Seq[T], size int) <-chan T {
+	ch := make(chan T, max(size, 0))
+	go func() {
+		defer close(ch)
+		if ctx.Err() != nil {
+			return
+		}
+		for v := range s {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Lines returns an iterator that generates the lines read from r, without the
+// end-of-line marker, paired with a nil error. If reading fails, the iterator
+// generates the error paired with an empty string as its last pair. Lines
+// longer than bufio.MaxScanTokenSize are reported as bufio.ErrTooLong.
+func Lines(r io.Reader) iter.Seq2[string, error] {
+	return Records(r, bufio.ScanLines, func(b []byte) (string, error) {
+		return string(b), nil
+	})
+}
+
+// Records returns an iterator that splits the data read from r into tokens by
+// the split function, parses each token by the function parse and generates
+// the results paired with a nil error. The token passed to parse is only valid
+// until parse returns. If reading or parsing fails, the iterator generates the
+// error paired with the zero value of T as its last pair.
+func Records[T any](r io.Reader, split bufio.SplitFunc, parse func([]byte) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		scanner := bufio.NewScanner(r)
+		scanner.Split(split)
+		for scanner.Scan() {
+			v, err := parse(scanner.Bytes())
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// Merge returns an iterator that merges the sorted sequences ss into a single
+// sorted sequence. Equal elements are generated in the order of the sequences
+// in ss. Each sequence is consumed only as far as needed, so Merge is suitable
+// for k-way merges of large sorted inputs.
+func Merge[T cmp.Ordered](ss ...iter.Seq[T]) iter.Seq[T] {
+	return MergeFunc(cmp.Compare[T], ss...)
+}
+
+// MergeFunc is like Merge but uses the comparison function to order the elements.
+func MergeFunc[T any](cmp func(T, T) int, ss ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		h := &mergeHeap[T]{cmp: cmp, items: make([]mergeItem[T], 0, len(ss))}
+		for i, s := range ss {
+			next, stop := iter.Pull(s)
+			defer stop()
+			if v, ok := next(); ok {
+				h.items = append(h.items, mergeItem[T]{v: v, i: i, next: next})
+			}
+		}
+		heap.Init[mergeItem[T]](h)
+		for len(h.items) > 0 {
+			top := &h.items[0]
+			if !yield(top.v) {
+				return
+			}
+			if v, ok := top.next(); ok {
+				top.v = v
+				heap.Fix[mergeItem[T]](h, 0)
+			} else {
+				heap.Pop[mergeItem[T]](h)
+			}
+		}
+	}
+}
+
+// mergeItem is the head of a sequence being merged.
+type mergeItem[T any] struct {
+	v    T
+	i    int // index of the sequence
+	next func() (T, bool)
+}
+
+// mergeHeap implements the heap.Interface interface for the heads of the
+// sequences being merged.
+type mergeHeap[T any] struct {
+	cmp   func(T, T) int
+	items []mergeItem[T]
+}
+
+func (h *mergeHeap[T]) Len() int      { return len(h.items) }
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap[T]) Push(x mergeItem[T]) {
+	h.items = append(h.items, x)
+}
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.cmp(h.items[i].v, h.items[j].v); c != 0 {
+		return c < 0
+	}
+	return h.items[i].i < h.items[j].i
+}
+
+func (h *mergeHeap[T]) Pop() mergeItem[T] {
+	n := len(h.items) - 1
+	x := h.items[n]
+	h.items[n] = mergeItem[T]{}
+	h.items = h.items[:n]
+	return x
+}
@@ -0,0 +1,254 @@
+//go:build go1.23
+
+package iters_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/gopherd/core/container/iters"
+)
+
+func TestFromChan(t *testing.T) {
+	t.Run("closed channel", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
+		if got := collect(iters.FromChan(context.Background(), ch)); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Errorf("FromChan() = %v, want %v", got, []int{1, 2, 3})
+		}
+	})
+
+	t.Run("done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+			cancel()
+		}()
+		if got := collect(iters.FromChan(ctx, ch)); !reflect.DeepEqual(got, []int{1}) {
+			t.Errorf("FromChan() = %v, want %v", got, []int{1})
+		}
+	})
+
+	t.Run("early termination", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		for range iters.FromChan(context.Background(), ch) {
+			break
+		}
+		if len(ch) != 1 {
+			t.Errorf("Expected 1 value left in the channel, got %d", len(ch))
+		}
+	})
+}
+
+func TestToChan(t *testing.T) {
+	t.Run("exhausted", func(t *testing.T) {
+		var got []int
+		for v := range iters.ToChan(context.Background(), iters.List(1, 2, 3), 1) {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Errorf("ToChan() = %v, want %v", got, []int{1, 2, 3})
+		}
+	})
+
+	t.Run("done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := iters.ToChan(ctx, iters.Infinite(), 0)
+		if v := <-ch; v != 0 {
+			t.Errorf("Expected 0, got %d", v)
+		}
+		cancel()
+		for range ch {
+			// the channel is closed once the goroutine sees ctx is done
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		ctx := context.Background()
+		got := collect(iters.FromChan(ctx, iters.ToChan(ctx, iters.Range(0, 5, 1), 2)))
+		if want := []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+			t.Errorf("FromChan(ToChan()) = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", []string{}},
+		{"trailing newline", "a\nb\n", []string{"a", "b"}},
+		{"no trailing newline", "a\r\n\nb", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for line, err := range iters.Lines(strings.NewReader(tt.input)) {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				got = append(got, line)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Lines() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("read error", func(t *testing.T) {
+		errRead := errors.New("read failed")
+		r := iotest.DataErrReader(iotest.ErrReader(errRead))
+		var lastErr error
+		for _, err := range iters.Lines(r) {
+			lastErr = err
+		}
+		if !errors.Is(lastErr, errRead) {
+			t.Errorf("Expected error %v, got %v", errRead, lastErr)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		var lastErr error
+		for _, err := range iters.Lines(strings.NewReader(strings.Repeat("x", bufio.MaxScanTokenSize+1))) {
+			lastErr = err
+		}
+		if !errors.Is(lastErr, bufio.ErrTooLong) {
+			t.Errorf("Expected error %v, got %v", bufio.ErrTooLong, lastErr)
+		}
+	})
+}
+
+func TestRecords(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		var got []int
+		for v, err := range iters.Records(strings.NewReader("1 2\n3"), bufio.ScanWords, func(b []byte) (int, error) {
+			return strconv.Atoi(string(b))
+		}) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, v)
+		}
+		if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Records() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		var got []int
+		var lastErr error
+		for v, err := range iters.Records(strings.NewReader("1 x 3"), bufio.ScanWords, func(b []byte) (int, error) {
+			return strconv.Atoi(string(b))
+		}) {
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1}) || !errors.Is(lastErr, strconv.ErrSyntax) {
+			t.Errorf("Expected [1] and a syntax error, got %v and %v", got, lastErr)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []iter.Seq[int]
+		expected []int
+	}{
+		{"none", nil, []int{}},
+		{"single", []iter.Seq[int]{iters.List(1, 2, 3)}, []int{1, 2, 3}},
+		{"several", []iter.Seq[int]{iters.List(1, 4, 7), iters.List[int](), iters.List(2, 5, 8, 9), iters.List(3, 6)}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"duplicates", []iter.Seq[int]{iters.List(1, 1, 3), iters.List(1, 3)}, []int{1, 1, 1, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(iters.Merge(tt.input...)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Merge() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("early termination", func(t *testing.T) {
+		s1, consumed1 := counted(100)
+		s2, consumed2 := counted(100)
+		got := collect(iters.Take(iters.Merge(s1, s2), 3))
+		if want := []int{0, 0, 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Merge() = %v, want %v", got, want)
+		}
+		// Merge holds the next element of each sequence
+		if *consumed1 != 2 || *consumed2 != 2 {
+			t.Errorf("Expected 2 and 2 elements consumed, got %d and %d", *consumed1, *consumed2)
+		}
+	})
+}
+
+func TestMergeFunc(t *testing.T) {
+	type record struct {
+		key    int
+		source string
+	}
+	byKey := func(a, b record) int { return a.key - b.key }
+	got := collect(iters.MergeFunc(byKey,
+		iters.List(record{1, "a"}, record{2, "a"}),
+		iters.List(record{1, "b"}, record{3, "b"}),
+	))
+	want := []record{{1, "a"}, {1, "b"}, {2, "a"}, {3, "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeFunc() = %v, want %v", got, want)
+	}
+}
+
+func ExampleMerge() {
+	a := iters.List(1, 4, 7)
+	b := iters.List(2, 5, 8)
+	c := iters.List(3, 6, 9)
+	for v := range iters.Merge(a, b, c) {
+		fmt.Printf("%d ", v)
+	}
+	// Output: 1 2 3 4 5 6 7 8 9
+}
+
+func ExampleLines() {
+	r := strings.NewReader("first\nsecond\n")
+	for line, err := range iters.Lines(r) {
+		if err != nil {
+			break
+		}
+		fmt.Println(line)
+	}
+	// Output:
+	// first
+	// second
+}
+
+func BenchmarkMerge(b *testing.B) {
+	const k, n = 16, 1000
+	ss := make([]iter.Seq[int], k)
+	for i := range ss {
+		ss[i] = iters.Range(i, k*n, k)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range iters.Merge(ss...) {
+		}
+	}
+}
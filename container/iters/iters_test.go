@@ -569,7 +569,7 @@ func TestSortValues(t *testing.T) {
 }
 
 // Example tests
-func ExampleEnumerateMap() {
+func Example_enumerateMap() {
 	m := map[string]int{"a": 1, "b": 2, "c": 3}
 	for k, v := range maps.All(m) {
 		fmt.Printf("%s: %d\n", k, v)
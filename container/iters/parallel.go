@@ -0,0 +1,211 @@
+//go:build go1.23
+
+package iters
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+type parallelOptions struct {
+	workers   int
+	unordered bool
+}
+
+// ParallelOption is a functional option for configuring the parallel iteration
+// functions ParallelMap, ParallelFilter and ParallelForEach.
+type ParallelOption func(*parallelOptions)
+
+// apply applies the options to the given options.
+func (o *parallelOptions) apply(opts []ParallelOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithWorkers sets the number of worker goroutines. The default is
+// runtime.GOMAXPROCS(0).
+func WithWorkers(workers int) ParallelOption {
+	return func(o *parallelOptions) {
+		o.workers = max(workers, 1)
+	}
+}
+
+// WithUnordered generates results in completion order instead of the order of
+// the source sequence, so that a slow element doesn't hold back the others.
+func WithUnordered() ParallelOption {
+	return func(o *parallelOptions) {
+		o.unordered = true
+	}
+}
+
+// ParallelMap returns an iterator that applies the function f to each element
+// in s concurrently and generates the results paired with a nil error.
+//
+// The results are generated in the order of s unless WithUnordered is given.
+// At most twice the number of workers elements are processed or buffered at
+// any time. If f returns an error, or ctx is done before all elements have
+// been processed, the remaining calls are cancelled and the iterator generates
+// the error paired with the zero value of U as its last pair.
+//
+// The goroutines are stopped before the iteration returns, including when the
+// caller stops the iteration early.
+func ParallelMap[T, U any](ctx context.Context, s iter.Seq[T], f func(context.Context, T) (U, error), opts ...ParallelOption) iter.Seq2[U, error] {
+	return parallel(ctx, s, func(ctx context.Context, v T) (U, bool, error) {
+		u, err := f(ctx, v)
+		return u, true, err
+	}, opts)
+}
+
+// ParallelFilter returns an iterator that calls the function f for each element
+// in s concurrently and generates the elements for which f returns true paired
+// with a nil error. Ordering, cancellation and error propagation are the same
+// as for ParallelMap.
+func ParallelFilter[T any](ctx context.Context, s iter.Seq[T], f func(context.Context, T) (bool, error), opts ...ParallelOption) iter.Seq2[T, error] {
+	return parallel(ctx, s, func(ctx context.Context, v T) (T, bool, error) {
+		ok, err := f(ctx, v)
+		return v, ok, err
+	}, opts)
+}
+
+// ParallelForEach calls the function f for each element in s concurrently and
+// waits for the calls to return. It returns the first error returned by f,
+// which cancels the remaining calls, or the error of ctx if it is done before
+// all elements have been processed. WithUnordered has no effect.
+func ParallelForEach[T any](ctx context.Context, s iter.Seq[T], f func(context.Context, T) error, opts ...ParallelOption) error {
+	opts = append(opts[:len(opts):len(opts)], WithUnordered())
+	for _, err := range parallel(ctx, s, func(ctx context.Context, v T) (struct{}, bool, error) {
+		return struct{}{}, false, f(ctx, v)
+	}, opts) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type parallelJob[T any] struct {
+	i int
+	v T
+}
+
+type parallelResult[U any] struct {
+	i    int
+	v    U
+	keep bool
+	err  error
+}
+
+// parallel implements the parallel iteration functions. The function f returns
+// the result for an element and whether the result is generated.
+func parallel[T, U any](parent context.Context, s iter.Seq[T], f func(context.Context, T) (U, bool, error), opts []ParallelOption) iter.Seq2[U, error] {
+	o := parallelOptions{workers: runtime.GOMAXPROCS(0)}
+	o.apply(opts)
+	return func(yield func(U, error) bool) {
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		jobs := make(chan parallelJob[T])
+		results := make(chan parallelResult[U])
+		// slots bounds the number of elements dispatched but not yet consumed,
+		// including the results buffered to restore the order.
+		slots := make(chan struct{}, 2*o.workers)
+
+		var dispatched int
+		var exhausted bool
+		fed := make(chan struct{})
+		go func() {
+			defer close(fed)
+			defer close(jobs)
+			for v := range s {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case jobs <- parallelJob[T]{dispatched, v}:
+				case <-ctx.Done():
+					return
+				}
+				dispatched++
+			}
+			exhausted = true
+		}()
+
+		var wg sync.WaitGroup
+		wg.Add(o.workers)
+		for w := 0; w < o.workers; w++ {
+			go func() {
+				defer wg.Done()
+				for job := range jobs {
+					v, keep, err := f(ctx, job.v)
+					select {
+					case results <- parallelResult[U]{job.i, v, keep, err}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		// stop cancels the goroutines and waits for them to return.
+		stop := func() {
+			cancel()
+			for range results {
+			}
+			<-fed
+		}
+		consumed := 0
+		// emit generates the result r, and reports whether to continue.
+		emit := func(r parallelResult[U]) bool {
+			<-slots
+			consumed++
+			return !r.keep || yield(r.v, nil)
+		}
+
+		var pending map[int]parallelResult[U]
+		if !o.unordered {
+			pending = make(map[int]parallelResult[U])
+		}
+		for r := range results {
+			if r.err != nil {
+				stop()
+				var zero U
+				yield(zero, r.err)
+				return
+			}
+			if o.unordered {
+				if !emit(r) {
+					stop()
+					return
+				}
+				continue
+			}
+			pending[r.i] = r
+			for {
+				r, ok := pending[consumed]
+				if !ok {
+					break
+				}
+				delete(pending, consumed)
+				if !emit(r) {
+					stop()
+					return
+				}
+			}
+		}
+		<-fed
+		if !exhausted || consumed < dispatched {
+			// only a done parent context stops the goroutines early here
+			var zero U
+			yield(zero, parent.Err())
+		}
+	}
+}
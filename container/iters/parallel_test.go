@@ -0,0 +1,265 @@
+//go:build go1.23
+
+package iters_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/container/iters"
+)
+
+func TestParallelMap(t *testing.T) {
+	square := func(_ context.Context, v int) (int, error) {
+		// make later elements complete first
+		time.Sleep(time.Duration(10-v%10) * 100 * time.Microsecond)
+		return v * v, nil
+	}
+	expected := make([]int, 100)
+	for i := range expected {
+		expected[i] = i * i
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		var got []int
+		for v, err := range iters.ParallelMap(context.Background(), iters.LessThan(100), square, iters.WithWorkers(8)) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("ParallelMap() = %v, want %v", got, expected)
+		}
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		var got []int
+		for v, err := range iters.ParallelMap(context.Background(), iters.LessThan(100), square, iters.WithWorkers(8), iters.WithUnordered()) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, v)
+		}
+		slices.Sort(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("ParallelMap() = %v, want %v", got, expected)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		for v, err := range iters.ParallelMap(context.Background(), iters.List[int](), square) {
+			t.Errorf("Unexpected pair (%d, %v)", v, err)
+		}
+	})
+
+	t.Run("bounded workers", func(t *testing.T) {
+		var running, peak atomic.Int32
+		f := func(_ context.Context, v int) (int, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Microsecond)
+			running.Add(-1)
+			return v, nil
+		}
+		for range iters.ParallelMap(context.Background(), iters.LessThan(50), f, iters.WithWorkers(3)) {
+		}
+		if p := peak.Load(); p > 3 {
+			t.Errorf("Expected at most 3 concurrent calls, got %d", p)
+		}
+	})
+
+	t.Run("early termination", func(t *testing.T) {
+		var calls atomic.Int32
+		f := func(_ context.Context, v int) (int, error) {
+			calls.Add(1)
+			return v, nil
+		}
+		for v := range iters.ParallelMap(context.Background(), iters.Infinite(), f, iters.WithWorkers(4)) {
+			if v == 10 {
+				break
+			}
+		}
+		n := calls.Load()
+		time.Sleep(time.Millisecond)
+		if calls.Load() != n {
+			t.Errorf("Expected no calls after the iteration returned")
+		}
+	})
+}
+
+func TestParallelMap_Error(t *testing.T) {
+	errFailed := errors.New("failed")
+	var calls atomic.Int32
+	f := func(ctx context.Context, v int) (int, error) {
+		calls.Add(1)
+		if v == 5 {
+			return 0, errFailed
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+		return v, nil
+	}
+	var lastErr error
+	count := 0
+	for _, err := range iters.ParallelMap(context.Background(), iters.Infinite(), f, iters.WithWorkers(4)) {
+		if lastErr != nil {
+			t.Fatalf("Expected the error to be the last pair")
+		}
+		lastErr = err
+		count++
+	}
+	if !errors.Is(lastErr, errFailed) {
+		t.Errorf("Expected error %v, got %v", errFailed, lastErr)
+	}
+	if count > 6 {
+		t.Errorf("Expected at most 6 pairs, got %d", count)
+	}
+}
+
+func TestParallelMap_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := func(_ context.Context, v int) (int, error) {
+		return v, nil
+	}
+	var lastErr error
+	for v, err := range iters.ParallelMap(ctx, iters.Infinite(), f, iters.WithWorkers(2)) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if v == 20 {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Errorf("Expected error %v, got %v", context.Canceled, lastErr)
+	}
+}
+
+func TestParallelFilter(t *testing.T) {
+	even := func(_ context.Context, v int) (bool, error) {
+		return v%2 == 0, nil
+	}
+	var got []int
+	for v, err := range iters.ParallelFilter(context.Background(), iters.LessThan(20), even, iters.WithWorkers(4)) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, v)
+	}
+	if want := []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParallelFilter() = %v, want %v", got, want)
+	}
+}
+
+func TestParallelForEach(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var sum atomic.Int64
+		err := iters.ParallelForEach(context.Background(), iters.LessThan(100), func(_ context.Context, v int) error {
+			sum.Add(int64(v))
+			return nil
+		})
+		if err != nil || sum.Load() != 4950 {
+			t.Errorf("Expected sum 4950 and no error, got %d and %v", sum.Load(), err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		var calls atomic.Int32
+		err := iters.ParallelForEach(context.Background(), iters.LessThan(1000), func(ctx context.Context, v int) error {
+			calls.Add(1)
+			if v == 3 {
+				return errFailed
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, iters.WithWorkers(4))
+		if !errors.Is(err, errFailed) {
+			t.Errorf("Expected error %v, got %v", errFailed, err)
+		}
+		if n := calls.Load(); n >= 1000 {
+			t.Errorf("Expected the remaining calls to be cancelled, got %d calls", n)
+		}
+	})
+
+	t.Run("done context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := iters.ParallelForEach(ctx, iters.Infinite(), func(context.Context, int) error {
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected error %v, got %v", context.Canceled, err)
+		}
+	})
+
+	t.Run("options not modified", func(t *testing.T) {
+		opts := make([]iters.ParallelOption, 1, 2)
+		opts[0] = iters.WithWorkers(2)
+		iters.ParallelForEach(context.Background(), iters.LessThan(10), func(context.Context, int) error {
+			return nil
+		}, opts...)
+		if opts[:2][1] != nil {
+			t.Errorf("Expected the spare capacity of the options to be left untouched")
+		}
+	})
+}
+
+func ExampleParallelMap() {
+	square := func(_ context.Context, v int) (int, error) {
+		return v * v, nil
+	}
+	for v, err := range iters.ParallelMap(context.Background(), iters.List(1, 2, 3, 4), square, iters.WithWorkers(2)) {
+		if err != nil {
+			break
+		}
+		fmt.Printf("%d ", v)
+	}
+	// Output: 1 4 9 16
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	work := func(_ context.Context, v int) (int, error) {
+		x := v
+		for i := 0; i < 10000; i++ {
+			x = x*31 + i
+		}
+		return x, nil
+	}
+	b.Run("Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for v := range iters.LessThan(100) {
+				work(context.Background(), v)
+			}
+		}
+	})
+	b.Run("Ordered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range iters.ParallelMap(context.Background(), iters.LessThan(100), work) {
+			}
+		}
+	})
+	b.Run("Unordered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range iters.ParallelMap(context.Background(), iters.LessThan(100), work, iters.WithUnordered()) {
+			}
+		}
+	})
+}
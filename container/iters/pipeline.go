@@ -0,0 +1,374 @@
+//go:build go1.23
+
+package iters
+
+import (
+	"iter"
+
+	"github.com/gopherd/core/container/pair"
+)
+
+// Take returns an iterator that generates the first n elements of s.
+// It stops consuming s once n elements have been generated.
+// It panics if n is negative.
+func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	if n < 0 {
+		panic("n must be non-negative")
+	}
+	return func(yield func(T) bool) {
+		if n == 0 {
+			return
+		}
+		i := 0
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// Take2 returns an iterator that generates the first n key-value pairs of m.
+// It stops consuming m once n pairs have been generated.
+// It panics if n is negative.
+func Take2[K, V any](m iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	if n < 0 {
+		panic("n must be non-negative")
+	}
+	return func(yield func(K, V) bool) {
+		if n == 0 {
+			return
+		}
+		i := 0
+		for k, v := range m {
+			if !yield(k, v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns an iterator that generates the elements of s except the first n.
+// It panics if n is negative.
+func Skip[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	if n < 0 {
+		panic("n must be non-negative")
+	}
+	return func(yield func(T) bool) {
+		i := 0
+		for v := range s {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Skip2 returns an iterator that generates the key-value pairs of m except the first n.
+// It panics if n is negative.
+func Skip2[K, V any](m iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	if n < 0 {
+		panic("n must be non-negative")
+	}
+	return func(yield func(K, V) bool) {
+		i := 0
+		for k, v := range m {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile returns an iterator that generates the elements of s as long as
+// the function f returns true. The first element for which f returns false
+// ends the sequence.
+func TakeWhile[T any](s iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range s {
+			if !f(v) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile2 returns an iterator that generates the key-value pairs of m as long
+// as the function f returns true. The first pair for which f returns false
+// ends the sequence.
+func TakeWhile2[K, V any](m iter.Seq2[K, V], f func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m {
+			if !f(k, v) || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// DropWhile returns an iterator that skips the elements of s as long as the
+// function f returns true, and generates the remaining elements.
+func DropWhile[T any](s iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		dropping := true
+		for v := range s {
+			if dropping {
+				if f(v) {
+					continue
+				}
+				dropping = false
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// DropWhile2 returns an iterator that skips the key-value pairs of m as long as
+// the function f returns true, and generates the remaining pairs.
+func DropWhile2[K, V any](m iter.Seq2[K, V], f func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		dropping := true
+		for k, v := range m {
+			if dropping {
+				if f(k, v) {
+					continue
+				}
+				dropping = false
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk returns an iterator that generates consecutive chunks of n elements
+// from s. The last chunk may have fewer than n elements. Each chunk is a new
+// slice which may be retained by the caller.
+// It panics if n is less than 1.
+func Chunk[T any](s iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("n must be positive")
+	}
+	return func(yield func([]T) bool) {
+		var chunk []T
+		for v := range s {
+			if chunk == nil {
+				chunk = make([]T, 0, n)
+			}
+			chunk = append(chunk, v)
+			if len(chunk) == n {
+				if !yield(chunk) {
+					return
+				}
+				chunk = nil
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Chunk2 returns an iterator that generates consecutive chunks of n key-value
+// pairs from m. The last chunk may have fewer than n pairs. Each chunk is a new
+// slice which may be retained by the caller.
+// It panics if n is less than 1.
+func Chunk2[K, V any](m iter.Seq2[K, V], n int) iter.Seq[[]pair.Pair[K, V]] {
+	return Chunk(pairs(m), n)
+}
+
+// Window returns an iterator that generates the sliding windows of n
+// consecutive elements from s. If s has fewer than n elements, no window is
+// generated. Each window is a new slice which may be retained by the caller.
+// It panics if n is less than 1.
+func Window[T any](s iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("n must be positive")
+	}
+	return func(yield func([]T) bool) {
+		// buf holds the current window twice from offset i, so that each
+		// window can be copied at once.
+		buf := make([]T, 2*n)
+		i, size := 0, 0
+		for v := range s {
+			buf[i], buf[i+n] = v, v
+			i++
+			if i == n {
+				i = 0
+			}
+			if size < n {
+				size++
+				if size < n {
+					continue
+				}
+			}
+			window := make([]T, n)
+			copy(window, buf[i:i+n])
+			if !yield(window) {
+				return
+			}
+		}
+	}
+}
+
+// Window2 returns an iterator that generates the sliding windows of n
+// consecutive key-value pairs from m. If m has fewer than n pairs, no window is
+// generated. Each window is a new slice which may be retained by the caller.
+// It panics if n is less than 1.
+func Window2[K, V any](m iter.Seq2[K, V], n int) iter.Seq[[]pair.Pair[K, V]] {
+	return Window(pairs(m), n)
+}
+
+// pairs returns an iterator that generates the key-value pairs of m as pairs.
+func pairs[K, V any](m iter.Seq2[K, V]) iter.Seq[pair.Pair[K, V]] {
+	return func(yield func(pair.Pair[K, V]) bool) {
+		for k, v := range m {
+			if !yield(pair.New(k, v)) {
+				return
+			}
+		}
+	}
+}
+
+// Flatten returns an iterator that generates the elements of each sequence in ss
+// one after another.
+func Flatten[T any](ss iter.Seq[iter.Seq[T]]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for s := range ss {
+			for v := range s {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Flatten2 returns an iterator that generates the key-value pairs of each
+// sequence in ms one after another.
+func Flatten2[K, V any](ms iter.Seq[iter.Seq2[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for m := range ms {
+			for k, v := range m {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FlatMap returns an iterator that applies the function f to each element in s
+// and generates the elements of the resulting sequences one after another.
+func FlatMap[T, U any](s iter.Seq[T], f func(T) iter.Seq[U]) iter.Seq[U] {
+	return Flatten(Map(s, f))
+}
+
+// FlatMap2 returns an iterator that applies the function f to each key-value
+// pair in m and generates the pairs of the resulting sequences one after another.
+func FlatMap2[K, V, K2, V2 any](m iter.Seq2[K, V], f func(K, V) iter.Seq2[K2, V2]) iter.Seq2[K2, V2] {
+	return Flatten2(Map2(m, f))
+}
+
+// Scan returns an iterator that generates the running accumulation of the
+// elements in s: each generated value is the result of applying the function f
+// to the previous result, starting with initial, and the next element.
+func Scan[T, U any](s iter.Seq[T], f func(U, T) U, initial U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		acc := initial
+		for v := range s {
+			acc = f(acc, v)
+			if !yield(acc) {
+				return
+			}
+		}
+	}
+}
+
+// Scan2 returns an iterator that generates each key of m with the running
+// accumulation of the key-value pairs up to it: each accumulated value is the
+// result of applying the function f to the previous result, starting with
+// initial, and the next pair.
+func Scan2[K, V, U any](m iter.Seq2[K, V], f func(U, K, V) U, initial U) iter.Seq2[K, U] {
+	return func(yield func(K, U) bool) {
+		acc := initial
+		for k, v := range m {
+			acc = f(acc, k, v)
+			if !yield(k, acc) {
+				return
+			}
+		}
+	}
+}
+
+// Interleave returns an iterator that generates the elements of the sequences
+// in ss in round-robin order: the first element of each sequence, then the
+// second element of each sequence, and so on. Exhausted sequences are skipped.
+func Interleave[T any](ss ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), 0, len(ss))
+		for _, s := range ss {
+			next, stop := iter.Pull(s)
+			defer stop()
+			nexts = append(nexts, next)
+		}
+		for len(nexts) > 0 {
+			for i := 0; i < len(nexts); {
+				v, ok := nexts[i]()
+				if !ok {
+					nexts = append(nexts[:i], nexts[i+1:]...)
+					continue
+				}
+				if !yield(v) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Interleave2 returns an iterator that generates the key-value pairs of the
+// sequences in ms in round-robin order. Exhausted sequences are skipped.
+func Interleave2[K, V any](ms ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		nexts := make([]func() (K, V, bool), 0, len(ms))
+		for _, m := range ms {
+			next, stop := iter.Pull2(m)
+			defer stop()
+			nexts = append(nexts, next)
+		}
+		for len(nexts) > 0 {
+			for i := 0; i < len(nexts); {
+				k, v, ok := nexts[i]()
+				if !ok {
+					nexts = append(nexts[:i], nexts[i+1:]...)
+					continue
+				}
+				if !yield(k, v) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
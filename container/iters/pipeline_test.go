@@ -0,0 +1,392 @@
+//go:build go1.23
+
+package iters_test
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/container/iters"
+	"github.com/gopherd/core/container/pair"
+)
+
+// counted returns a sequence of [0, n) and a counter of the elements consumed.
+func counted(n int) (iter.Seq[int], *int) {
+	var consumed int
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			consumed++
+			if !yield(i) {
+				return
+			}
+		}
+	}, &consumed
+}
+
+// collect2 collects the key-value pairs of m into a slice.
+func collect2[K, V any](m iter.Seq2[K, V]) []pair.Pair[K, V] {
+	result := make([]pair.Pair[K, V], 0)
+	for k, v := range m {
+		result = append(result, pair.New(k, v))
+	}
+	return result
+}
+
+func collect[T any](s iter.Seq[T]) []T {
+	result := make([]T, 0)
+	for v := range s {
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected []int
+		consumed int
+	}{
+		{"zero", 0, []int{}, 0},
+		{"fewer than length", 3, []int{0, 1, 2}, 3},
+		{"more than length", 10, []int{0, 1, 2, 3, 4}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, consumed := counted(5)
+			if got := collect(iters.Take(s, tt.n)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Take() = %v, want %v", got, tt.expected)
+			}
+			if *consumed != tt.consumed {
+				t.Errorf("Expected %d elements consumed, got %d", tt.consumed, *consumed)
+			}
+		})
+	}
+
+	t.Run("infinite", func(t *testing.T) {
+		if got := collect(iters.Take(iters.Infinite(), 3)); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+			t.Errorf("Take() = %v, want %v", got, []int{0, 1, 2})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for negative n")
+			}
+		}()
+		iters.Take(iters.Infinite(), -1)
+	})
+}
+
+func TestTake2(t *testing.T) {
+	got := collect2(iters.Take2(slices.All([]string{"a", "b", "c"}), 2))
+	want := []pair.Pair[int, string]{pair.New(0, "a"), pair.New(1, "b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Take2() = %v, want %v", got, want)
+	}
+	for range iters.Take2(slices.All([]string{"a", "b"}), 2) {
+		break
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		expected []int
+	}{
+		{"zero", 0, []int{0, 1, 2, 3, 4}},
+		{"fewer than length", 3, []int{3, 4}},
+		{"more than length", 10, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := counted(5)
+			if got := collect(iters.Skip(s, tt.n)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Skip() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("early termination", func(t *testing.T) {
+		s, consumed := counted(10)
+		for range iters.Skip(s, 2) {
+			break
+		}
+		if *consumed != 3 {
+			t.Errorf("Expected 3 elements consumed, got %d", *consumed)
+		}
+	})
+}
+
+func TestSkip2(t *testing.T) {
+	got := collect2(iters.Skip2(slices.All([]string{"a", "b", "c"}), 2))
+	want := []pair.Pair[int, string]{pair.New(2, "c")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Skip2() = %v, want %v", got, want)
+	}
+}
+
+func TestTakeWhile(t *testing.T) {
+	s, consumed := counted(10)
+	got := collect(iters.TakeWhile(s, func(v int) bool { return v < 3 }))
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TakeWhile() = %v, want %v", got, want)
+	}
+	if *consumed != 4 {
+		t.Errorf("Expected 4 elements consumed, got %d", *consumed)
+	}
+
+	got2 := collect2(iters.TakeWhile2(slices.All([]int{1, 2, 5, 1}), func(_, v int) bool { return v < 5 }))
+	if want := []pair.Pair[int, int]{pair.New(0, 1), pair.New(1, 2)}; !reflect.DeepEqual(got2, want) {
+		t.Errorf("TakeWhile2() = %v, want %v", got2, want)
+	}
+}
+
+func TestDropWhile(t *testing.T) {
+	got := collect(iters.DropWhile(iters.List(1, 2, 5, 1, 6), func(v int) bool { return v < 5 }))
+	if want := []int{5, 1, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DropWhile() = %v, want %v", got, want)
+	}
+
+	got2 := collect2(iters.DropWhile2(slices.All([]int{1, 5, 1}), func(_, v int) bool { return v < 5 }))
+	if want := []pair.Pair[int, int]{pair.New(1, 5), pair.New(2, 1)}; !reflect.DeepEqual(got2, want) {
+		t.Errorf("DropWhile2() = %v, want %v", got2, want)
+	}
+
+	for range iters.DropWhile(iters.Infinite(), func(v int) bool { return v < 5 }) {
+		break
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		n        int
+		expected [][]int
+	}{
+		{"empty", 0, 2, [][]int{}},
+		{"exact", 4, 2, [][]int{{0, 1}, {2, 3}}},
+		{"remainder", 5, 2, [][]int{{0, 1}, {2, 3}, {4}}},
+		{"single chunk", 3, 5, [][]int{{0, 1, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := counted(tt.length)
+			if got := collect(iters.Chunk(s, tt.n)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Chunk() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("early termination", func(t *testing.T) {
+		s, consumed := counted(10)
+		for range iters.Chunk(s, 3) {
+			break
+		}
+		if *consumed != 3 {
+			t.Errorf("Expected 3 elements consumed, got %d", *consumed)
+		}
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for n < 1")
+			}
+		}()
+		iters.Chunk(iters.List(1), 0)
+	})
+}
+
+func TestChunk2(t *testing.T) {
+	got := collect(iters.Chunk2(slices.All([]string{"a", "b", "c"}), 2))
+	want := [][]pair.Pair[int, string]{
+		{pair.New(0, "a"), pair.New(1, "b")},
+		{pair.New(2, "c")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Chunk2() = %v, want %v", got, want)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		n        int
+		expected [][]int
+	}{
+		{"shorter than window", 2, 3, [][]int{}},
+		{"equal to window", 3, 3, [][]int{{0, 1, 2}}},
+		{"sliding", 5, 3, [][]int{{0, 1, 2}, {1, 2, 3}, {2, 3, 4}}},
+		{"size one", 3, 1, [][]int{{0}, {1}, {2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := counted(tt.length)
+			if got := collect(iters.Window(s, tt.n)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Window() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("early termination", func(t *testing.T) {
+		for range iters.Window(iters.Infinite(), 2) {
+			break
+		}
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected panic for n < 1")
+			}
+		}()
+		iters.Window(iters.List(1), 0)
+	})
+}
+
+func TestWindow2(t *testing.T) {
+	got := collect(iters.Window2(slices.All([]string{"a", "b", "c"}), 2))
+	want := [][]pair.Pair[int, string]{
+		{pair.New(0, "a"), pair.New(1, "b")},
+		{pair.New(1, "b"), pair.New(2, "c")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Window2() = %v, want %v", got, want)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	got := collect(iters.Flatten(iters.List(iters.List(1, 2), iters.List[int](), iters.List(3))))
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() = %v, want %v", got, want)
+	}
+
+	got2 := collect2(iters.Flatten2(iters.List(slices.All([]string{"a"}), slices.All([]string{"b", "c"}))))
+	want2 := []pair.Pair[int, string]{pair.New(0, "a"), pair.New(0, "b"), pair.New(1, "c")}
+	if !reflect.DeepEqual(got2, want2) {
+		t.Errorf("Flatten2() = %v, want %v", got2, want2)
+	}
+
+	for range iters.Flatten(iters.List(iters.Infinite())) {
+		break
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	got := collect(iters.FlatMap(iters.List(1, 2, 3), func(v int) iter.Seq[int] {
+		return iters.Repeat(v, v)
+	}))
+	if want := []int{1, 2, 2, 3, 3, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FlatMap() = %v, want %v", got, want)
+	}
+
+	got2 := collect2(iters.FlatMap2(slices.All([]string{"ab", "c"}), func(i int, s string) iter.Seq2[int, byte] {
+		return func(yield func(int, byte) bool) {
+			for j := 0; j < len(s); j++ {
+				if !yield(i, s[j]) {
+					return
+				}
+			}
+		}
+	}))
+	want2 := []pair.Pair[int, byte]{pair.New(0, byte('a')), pair.New(0, byte('b')), pair.New(1, byte('c'))}
+	if !reflect.DeepEqual(got2, want2) {
+		t.Errorf("FlatMap2() = %v, want %v", got2, want2)
+	}
+}
+
+func TestScan(t *testing.T) {
+	got := collect(iters.Scan(iters.List(1, 2, 3, 4), func(acc, v int) int { return acc + v }, 10))
+	if want := []int{11, 13, 16, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() = %v, want %v", got, want)
+	}
+
+	got2 := collect2(iters.Scan2(slices.All([]int{1, 2, 3}), func(acc string, i, v int) string {
+		return acc + fmt.Sprint(i*v)
+	}, ">"))
+	want2 := []pair.Pair[int, string]{pair.New(0, ">0"), pair.New(1, ">02"), pair.New(2, ">026")}
+	if !reflect.DeepEqual(got2, want2) {
+		t.Errorf("Scan2() = %v, want %v", got2, want2)
+	}
+
+	for range iters.Scan(iters.Infinite(), func(acc, v int) int { return acc + v }, 0) {
+		break
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []iter.Seq[int]
+		expected []int
+	}{
+		{"none", nil, []int{}},
+		{"equal length", []iter.Seq[int]{iters.List(1, 4), iters.List(2, 5), iters.List(3, 6)}, []int{1, 2, 3, 4, 5, 6}},
+		{"different lengths", []iter.Seq[int]{iters.List(1), iters.List(2, 4, 5), iters.List[int](), iters.List(3, 6)}, []int{1, 2, 3, 4, 6, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(iters.Interleave(tt.input...)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Interleave() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("early termination", func(t *testing.T) {
+		s1, consumed1 := counted(10)
+		s2, consumed2 := counted(10)
+		if got := collect(iters.Take(iters.Interleave(s1, s2), 3)); !reflect.DeepEqual(got, []int{0, 0, 1}) {
+			t.Errorf("Interleave() = %v, want %v", got, []int{0, 0, 1})
+		}
+		if *consumed1 != 2 || *consumed2 != 1 {
+			t.Errorf("Expected 2 and 1 elements consumed, got %d and %d", *consumed1, *consumed2)
+		}
+	})
+}
+
+func TestInterleave2(t *testing.T) {
+	got := collect2(iters.Interleave2(slices.All([]string{"a", "c"}), slices.All([]string{"b"})))
+	want := []pair.Pair[int, string]{pair.New(0, "a"), pair.New(0, "b"), pair.New(1, "c")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Interleave2() = %v, want %v", got, want)
+	}
+	for range iters.Interleave2(slices.All([]string{"a"})) {
+		break
+	}
+}
+
+func ExampleChunk() {
+	for chunk := range iters.Chunk(iters.Range(1, 8, 1), 3) {
+		fmt.Println(chunk)
+	}
+	// Output:
+	// [1 2 3]
+	// [4 5 6]
+	// [7]
+}
+
+func ExampleWindow() {
+	for window := range iters.Window(iters.List(1, 2, 3, 4), 2) {
+		fmt.Println(window)
+	}
+	// Output:
+	// [1 2]
+	// [2 3]
+	// [3 4]
+}
+
+func ExampleTake() {
+	evens := iters.Filter(iters.Infinite(), func(v int) bool { return v%2 == 0 })
+	for v := range iters.Take(iters.Skip(evens, 1), 3) {
+		fmt.Printf("%d ", v)
+	}
+	// Output: 2 4 6
+}
@@ -0,0 +1,431 @@
+//go:build go1.23
+
+package iters
+
+import (
+	"cmp"
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/gopherd/core/constraints"
+	"github.com/gopherd/core/container/heap"
+)
+
+// Summary accumulates the count, mean and variance of a stream of numbers in
+// a single pass using Welford's algorithm, which is numerically stable.
+// The zero value is an empty summary ready to use.
+type Summary struct {
+	n    int
+	mean float64
+	m2   float64 // sum of squares of differences from the mean
+	min  float64
+	max  float64
+}
+
+// Summarize returns the summary of the sequence s.
+func Summarize[T constraints.Real](s iter.Seq[T]) Summary {
+	var sum Summary
+	for v := range s {
+		sum.Add(float64(v))
+	}
+	return sum
+}
+
+// Add adds x to the summary.
+func (s *Summary) Add(x float64) {
+	s.n++
+	if s.n == 1 {
+		s.min, s.max = x, x
+	} else {
+		s.min = min(s.min, x)
+		s.max = max(s.max, x)
+	}
+	d := x - s.mean
+	s.mean += d / float64(s.n)
+	s.m2 += d * (x - s.mean)
+}
+
+// Count returns the number of values added.
+func (s Summary) Count() int { return s.n }
+
+// Mean returns the arithmetic mean, or NaN if the summary is empty.
+func (s Summary) Mean() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.mean
+}
+
+// Min returns the least value, or NaN if the summary is empty.
+func (s Summary) Min() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.min
+}
+
+// Max returns the greatest value, or NaN if the summary is empty.
+func (s Summary) Max() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.max
+}
+
+// Variance returns the population variance, or NaN if the summary is empty.
+func (s Summary) Variance() float64 {
+	if s.n == 0 {
+		return math.NaN()
+	}
+	return s.m2 / float64(s.n)
+}
+
+// SampleVariance returns the unbiased sample variance, or NaN if fewer than
+// two values have been added.
+func (s Summary) SampleVariance() float64 {
+	if s.n < 2 {
+		return math.NaN()
+	}
+	return s.m2 / float64(s.n-1)
+}
+
+// StdDev returns the population standard deviation, or NaN if the summary is empty.
+func (s Summary) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
+
+// Mean returns the arithmetic mean of the sequence s.
+// It panics if s is empty.
+func Mean[T constraints.Real](s iter.Seq[T]) float64 {
+	sum := Summarize(s)
+	if sum.n == 0 {
+		panic("empty sequence")
+	}
+	return sum.mean
+}
+
+// Variance returns the population variance of the sequence s.
+// It panics if s is empty.
+func Variance[T constraints.Real](s iter.Seq[T]) float64 {
+	sum := Summarize(s)
+	if sum.n == 0 {
+		panic("empty sequence")
+	}
+	return sum.Variance()
+}
+
+// StdDev returns the population standard deviation of the sequence s.
+// It panics if s is empty.
+func StdDev[T constraints.Real](s iter.Seq[T]) float64 {
+	return math.Sqrt(Variance(s))
+}
+
+// Median returns the median of the sequence s, which is the mean of the two
+// middle elements if s has an even number of elements. It stores the
+// elements; use Quantile for an approximation in constant memory.
+// It panics if s is empty.
+func Median[T constraints.Real](s iter.Seq[T]) float64 {
+	values := slices.Collect(s)
+	if len(values) == 0 {
+		panic("empty sequence")
+	}
+	slices.Sort(values)
+	return quantile(values, 0.5)
+}
+
+// quantile returns the p-quantile of the sorted values by linear interpolation
+// between the closest ranks.
+func quantile[T constraints.Real](sorted []T, p float64) float64 {
+	h := float64(len(sorted)-1) * p
+	i := int(h)
+	if i+1 >= len(sorted) {
+		return float64(sorted[len(sorted)-1])
+	}
+	lo, hi := float64(sorted[i]), float64(sorted[i+1])
+	return lo + (h-float64(i))*(hi-lo)
+}
+
+// exactValues is the number of values a QuantileEstimator stores before it
+// switches to the P² algorithm.
+const exactValues = 32
+
+// QuantileEstimator estimates a quantile of a stream of numbers in constant
+// memory using the P² algorithm of Jain and Chlamtac. The first 32 values are
+// stored, so the estimate is exact for up to 32 values, and they initialize
+// the markers of the algorithm so that the estimate is accurate early on even
+// for extreme quantiles.
+type QuantileEstimator struct {
+	p      float64
+	n      int
+	values [exactValues]float64 // the first values
+	q      [5]float64           // marker heights
+	np     [5]int               // marker positions
+	dp     [5]float64           // desired marker positions
+}
+
+// NewQuantileEstimator creates a QuantileEstimator of the p-quantile, for
+// example 0.5 for the median or 0.99 for the 99th percentile.
+// It panics if p is not in [0, 1].
+func NewQuantileEstimator(p float64) *QuantileEstimator {
+	if !(p >= 0 && p <= 1) {
+		panic("p must be in [0, 1]")
+	}
+	return &QuantileEstimator{p: p}
+}
+
+// Count returns the number of values added.
+func (e *QuantileEstimator) Count() int { return e.n }
+
+// Add adds x to the estimator.
+func (e *QuantileEstimator) Add(x float64) {
+	if e.n < len(e.values) {
+		e.values[e.n] = x
+		e.n++
+		return
+	}
+	if e.n == len(e.values) {
+		e.init()
+	}
+	e.n++
+
+	// find the cell k such that q[k] <= x < q[k+1], adjusting the extremes
+	var k int
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+		k = 0
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= e.q[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < len(e.np); i++ {
+		e.np[i]++
+	}
+	p := e.p
+	increments := [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+	for i := range e.dp {
+		e.dp[i] += increments[i]
+	}
+
+	// adjust the heights of the middle markers if they are off their
+	// desired positions
+	for i := 1; i <= 3; i++ {
+		d := e.dp[i] - float64(e.np[i])
+		if (d >= 1 && e.np[i+1]-e.np[i] > 1) || (d <= -1 && e.np[i-1]-e.np[i] < -1) {
+			step := 1
+			if d < 0 {
+				step = -1
+			}
+			q := e.parabolic(i, step)
+			if !(e.q[i-1] < q && q < e.q[i+1]) {
+				q = e.linear(i, step)
+			}
+			e.q[i] = q
+			e.np[i] += step
+		}
+	}
+}
+
+// init initializes the markers from the stored values.
+func (e *QuantileEstimator) init() {
+	sorted := e.values
+	slices.Sort(sorted[:])
+	last := float64(len(sorted) - 1)
+	p := e.p
+	e.dp = [5]float64{0, last * p / 2, last * p, last * (1 + p) / 2, last}
+	for i, d := range e.dp {
+		e.np[i] = int(math.Round(d))
+	}
+	// the middle markers must be at distinct positions between the extremes
+	for i := 1; i <= 3; i++ {
+		e.np[i] = max(e.np[i], e.np[i-1]+1)
+	}
+	for i := 3; i >= 1; i-- {
+		e.np[i] = min(e.np[i], e.np[i+1]-1)
+	}
+	for i, n := range e.np {
+		e.q[i] = sorted[n]
+	}
+}
+
+func (e *QuantileEstimator) parabolic(i, d int) float64 {
+	q, n := &e.q, &e.np
+	fd := float64(d)
+	return q[i] + fd/float64(n[i+1]-n[i-1])*(float64(n[i]-n[i-1]+d)*(q[i+1]-q[i])/float64(n[i+1]-n[i])+
+		float64(n[i+1]-n[i]-d)*(q[i]-q[i-1])/float64(n[i]-n[i-1]))
+}
+
+func (e *QuantileEstimator) linear(i, d int) float64 {
+	return e.q[i] + float64(d)*(e.q[i+d]-e.q[i])/float64(e.np[i+d]-e.np[i])
+}
+
+// Value returns the estimated quantile, or NaN if no values have been added.
+func (e *QuantileEstimator) Value() float64 {
+	if e.n == 0 {
+		return math.NaN()
+	}
+	if e.n <= len(e.values) {
+		values := e.values
+		slices.Sort(values[:e.n])
+		return quantile(values[:e.n], e.p)
+	}
+	return e.q[2]
+}
+
+// Quantile returns an approximation of the p-quantile of the sequence s in a
+// single pass and constant memory. See QuantileEstimator for details.
+// It panics if s is empty or p is not in [0, 1].
+func Quantile[T constraints.Real](s iter.Seq[T], p float64) float64 {
+	e := NewQuantileEstimator(p)
+	for v := range s {
+		e.Add(float64(v))
+	}
+	if e.n == 0 {
+		panic("empty sequence")
+	}
+	return e.Value()
+}
+
+// Histogram returns the number of elements of the sequence s in each bucket
+// delimited by the bounds, which must be strictly increasing. The result has
+// len(bounds)+1 buckets: bucket i counts the elements v such that
+// bounds[i-1] <= v < bounds[i], where the first and last buckets are unbounded
+// below and above respectively.
+// It panics if the bounds are not strictly increasing.
+func Histogram[T constraints.Real](s iter.Seq[T], bounds []T) []int {
+	for i := 1; i < len(bounds); i++ {
+		if !(bounds[i-1] < bounds[i]) {
+			panic("bounds must be strictly increasing")
+		}
+	}
+	counts := make([]int, len(bounds)+1)
+	for v := range s {
+		i, found := slices.BinarySearch(bounds, v)
+		if found {
+			i++
+		}
+		counts[i]++
+	}
+	return counts
+}
+
+// LinearBuckets returns count bucket bounds for Histogram, starting at start
+// and spaced width apart.
+// It panics if count is negative or width is not positive.
+func LinearBuckets[T constraints.Real](start, width T, count int) []T {
+	if count < 0 {
+		panic("count must be non-negative")
+	}
+	if !(width > 0) {
+		panic("width must be positive")
+	}
+	bounds := make([]T, count)
+	for i := range bounds {
+		bounds[i] = start + T(i)*width
+	}
+	return bounds
+}
+
+// ExponentialBuckets returns count bucket bounds for Histogram, starting at
+// start and each factor times the previous one.
+// It panics if count is negative, start is not positive or factor is not
+// greater than 1.
+func ExponentialBuckets[T constraints.Real](start, factor T, count int) []T {
+	if count < 0 {
+		panic("count must be non-negative")
+	}
+	if !(start > 0) {
+		panic("start must be positive")
+	}
+	if !(factor > 1) {
+		panic("factor must be greater than 1")
+	}
+	bounds := make([]T, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+// Mode returns the most frequent element in the sequence s. If several elements
+// are equally frequent, the one which reached that frequency first is returned.
+// It panics if s is empty.
+func Mode[T comparable](s iter.Seq[T]) T {
+	counts := make(map[T]int)
+	var mode T
+	best := 0
+	for v := range s {
+		c := counts[v] + 1
+		counts[v] = c
+		if c > best {
+			mode, best = v, c
+		}
+	}
+	if best == 0 {
+		panic("empty sequence")
+	}
+	return mode
+}
+
+// TopK returns the k greatest elements in the sequence s in descending order.
+// It keeps only k elements in memory. If s has fewer than k elements, all of
+// them are returned.
+// It panics if k is negative.
+func TopK[T cmp.Ordered](s iter.Seq[T], k int) []T {
+	return TopKFunc(s, k, cmp.Compare[T])
+}
+
+// TopKFunc returns the k greatest elements in the sequence s according to the
+// comparison function, in descending order. If there are more than k such
+// elements because of ties, the earlier ones are kept.
+// It panics if k is negative.
+func TopKFunc[T any](s iter.Seq[T], k int, cmp func(T, T) int) []T {
+	if k < 0 {
+		panic("k must be non-negative")
+	}
+	if k == 0 {
+		return []T{}
+	}
+	// h is a min-heap of the k greatest elements seen so far, so the least of
+	// them is replaced when a greater element is seen
+	h := &topKHeap[T]{cmp: cmp, data: make([]T, 0, k)}
+	for v := range s {
+		if len(h.data) < k {
+			heap.Push[T](h, v)
+		} else if cmp(v, h.data[0]) > 0 {
+			h.data[0] = v
+			heap.Fix[T](h, 0)
+		}
+	}
+	result := make([]T, len(h.data))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop[T](h)
+	}
+	return result
+}
+
+// topKHeap implements the heap.Interface interface as a min-heap ordered by
+// the comparison function.
+type topKHeap[T any] struct {
+	cmp  func(T, T) int
+	data []T
+}
+
+func (h *topKHeap[T]) Len() int           { return len(h.data) }
+func (h *topKHeap[T]) Less(i, j int) bool { return h.cmp(h.data[i], h.data[j]) < 0 }
+func (h *topKHeap[T]) Swap(i, j int)      { h.data[i], h.data[j] = h.data[j], h.data[i] }
+func (h *topKHeap[T]) Push(x T)           { h.data = append(h.data, x) }
+
+func (h *topKHeap[T]) Pop() T {
+	var zero T
+	n := len(h.data) - 1
+	x := h.data[n]
+	h.data[n] = zero
+	h.data = h.data[:n]
+	return x
+}
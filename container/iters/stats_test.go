@@ -0,0 +1,285 @@
+//go:build go1.23
+
+package iters_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/container/iters"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected %s to panic", name)
+		}
+	}()
+	f()
+}
+
+func TestSummary(t *testing.T) {
+	var s iters.Summary
+	if s.Count() != 0 || !math.IsNaN(s.Mean()) || !math.IsNaN(s.Variance()) || !math.IsNaN(s.Min()) {
+		t.Errorf("Expected empty summary, got %+v", s)
+	}
+
+	s = iters.Summarize(iters.List(2, 4, 4, 4, 5, 5, 7, 9))
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"Mean", s.Mean(), 5},
+		{"Variance", s.Variance(), 4},
+		{"SampleVariance", s.SampleVariance(), 32.0 / 7},
+		{"StdDev", s.StdDev(), 2},
+		{"Min", s.Min(), 2},
+		{"Max", s.Max(), 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !almostEqual(tt.got, tt.expected, 1e-12) {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("numerical stability", func(t *testing.T) {
+		// a naive sum of squares loses all precision with such an offset
+		s := iters.Summarize(iters.List(1e9+4, 1e9+7, 1e9+13, 1e9+16))
+		if !almostEqual(s.Variance(), 22.5, 1e-6) {
+			t.Errorf("Variance() = %v, want %v", s.Variance(), 22.5)
+		}
+	})
+}
+
+func TestMeanVarianceStdDev(t *testing.T) {
+	if got := iters.Mean(iters.List(1, 2, 3, 4)); got != 2.5 {
+		t.Errorf("Mean() = %v, want %v", got, 2.5)
+	}
+	if got := iters.Variance(iters.List(1.0, 3.0)); got != 1 {
+		t.Errorf("Variance() = %v, want %v", got, 1)
+	}
+	if got := iters.StdDev(iters.List(uint8(10), uint8(10))); got != 0 {
+		t.Errorf("StdDev() = %v, want %v", got, 0)
+	}
+	expectPanic(t, "Mean of empty sequence", func() { iters.Mean(iters.List[int]()) })
+	expectPanic(t, "Variance of empty sequence", func() { iters.Variance(iters.List[int]()) })
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		expected float64
+	}{
+		{"single", []int{7}, 7},
+		{"odd", []int{5, 1, 3}, 3},
+		{"even", []int{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iters.Median(iters.Enumerate(tt.input)); got != tt.expected {
+				t.Errorf("Median() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+	expectPanic(t, "Median of empty sequence", func() { iters.Median(iters.List[int]()) })
+}
+
+func TestQuantile(t *testing.T) {
+	t.Run("few values are exact", func(t *testing.T) {
+		if got := iters.Quantile(iters.List(4, 1, 3, 2), 0.5); got != 2.5 {
+			t.Errorf("Quantile() = %v, want %v", got, 2.5)
+		}
+		if got := iters.Quantile(iters.List(4, 1, 3), 1); got != 4 {
+			t.Errorf("Quantile() = %v, want %v", got, 4)
+		}
+	})
+
+	t.Run("extreme quantiles of few values", func(t *testing.T) {
+		tests := []struct {
+			input    []int
+			p        float64
+			expected float64
+		}{
+			{[]int{1, 2, 3, 4, 5}, 0.9, 4.6},
+			{[]int{5, 4, 3, 2, 1}, 0.1, 1.4},
+			{[]int{1, 2, 3, 4, 5, 6}, 0.9, 5.5},
+			{[]int{6, 1, 5, 2, 4, 3}, 0.99, 5.95},
+		}
+		for _, tt := range tests {
+			if got := iters.Quantile(iters.Enumerate(tt.input), tt.p); !almostEqual(got, tt.expected, 1e-9) {
+				t.Errorf("Quantile(%v, %v) = %v, want %v", tt.input, tt.p, got, tt.expected)
+			}
+		}
+	})
+
+	t.Run("small sequences", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		for _, p := range []float64{0.1, 0.9, 0.99} {
+			for n := 1; n <= 200; n++ {
+				got := iters.Quantile(iters.Enumerate(r.Perm(n)), p)
+				exact := p * float64(n-1)
+				// exact for up to 32 values, then within a few ranks
+				tolerance := 1e-9
+				if n > 32 {
+					tolerance = 0.1 * float64(n)
+				}
+				if !almostEqual(got, exact, tolerance) {
+					t.Errorf("Quantile(%v) of %d values = %v, want about %v", p, n, got, exact)
+				}
+			}
+		}
+	})
+
+	t.Run("approximation", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		values := make([]float64, 100000)
+		for i := range values {
+			values[i] = r.NormFloat64()*10 + 100
+		}
+		sorted := slices.Clone(values)
+		slices.Sort(sorted)
+		for _, p := range []float64{0.01, 0.25, 0.5, 0.9, 0.99} {
+			got := iters.Quantile(iters.Enumerate(values), p)
+			exact := sorted[int(p*float64(len(sorted)-1))]
+			if !almostEqual(got, exact, 0.5) {
+				t.Errorf("Quantile(%v) = %v, want about %v", p, got, exact)
+			}
+		}
+	})
+
+	t.Run("sorted input", func(t *testing.T) {
+		e := iters.NewQuantileEstimator(0.9)
+		for i := 0; i < 10000; i++ {
+			e.Add(float64(i))
+		}
+		if got := e.Value(); !almostEqual(got, 9000, 10) || e.Count() != 10000 {
+			t.Errorf("Value() = %v, want about %v", got, 9000)
+		}
+	})
+
+	expectPanic(t, "Quantile of empty sequence", func() { iters.Quantile(iters.List[int](), 0.5) })
+	expectPanic(t, "NewQuantileEstimator with invalid p", func() { iters.NewQuantileEstimator(1.5) })
+	if v := iters.NewQuantileEstimator(0.5).Value(); !math.IsNaN(v) {
+		t.Errorf("Expected NaN for an empty estimator, got %v", v)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		bounds   []int
+		expected []int
+	}{
+		{"no bounds", []int{1, 2, 3}, nil, []int{3}},
+		{"empty", nil, []int{10}, []int{0, 0}},
+		{"buckets", []int{-5, 0, 9, 10, 15, 20, 100}, []int{0, 10, 20}, []int{1, 2, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iters.Histogram(iters.Enumerate(tt.input), tt.bounds); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Histogram() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+	expectPanic(t, "Histogram with unsorted bounds", func() { iters.Histogram(iters.List(1), []int{2, 2}) })
+}
+
+func TestBuckets(t *testing.T) {
+	if got, want := iters.LinearBuckets(0.5, 1, 3), []float64{0.5, 1.5, 2.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LinearBuckets() = %v, want %v", got, want)
+	}
+	if got, want := iters.ExponentialBuckets(1, 2, 4), []int{1, 2, 4, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExponentialBuckets() = %v, want %v", got, want)
+	}
+	expectPanic(t, "LinearBuckets with zero width", func() { iters.LinearBuckets(0, 0, 1) })
+	expectPanic(t, "ExponentialBuckets with factor 1", func() { iters.ExponentialBuckets(1, 1, 1) })
+}
+
+func TestMode(t *testing.T) {
+	if got := iters.Mode(iters.List("a", "b", "b", "c", "a", "b")); got != "b" {
+		t.Errorf("Mode() = %v, want %v", got, "b")
+	}
+	if got := iters.Mode(iters.List(1, 2, 2, 1)); got != 2 {
+		t.Errorf("Mode() = %v, want %v", got, 2)
+	}
+	expectPanic(t, "Mode of empty sequence", func() { iters.Mode(iters.List[int]()) })
+}
+
+func TestTopK(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int
+		k        int
+		expected []int
+	}{
+		{"zero", []int{1, 2}, 0, []int{}},
+		{"fewer than k", []int{2, 3, 1}, 5, []int{3, 2, 1}},
+		{"top three", []int{5, 1, 9, 3, 7, 9, 2}, 3, []int{9, 9, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iters.TopK(iters.Enumerate(tt.input), tt.k); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("TopK() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("func", func(t *testing.T) {
+		type item struct {
+			name  string
+			score int
+		}
+		got := iters.TopKFunc(iters.List(item{"a", 1}, item{"b", 3}, item{"c", 3}, item{"d", 2}), 1, func(a, b item) int {
+			return a.score - b.score
+		})
+		if want := []item{{"b", 3}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("TopKFunc() = %v, want %v", got, want)
+		}
+	})
+
+	expectPanic(t, "TopK with negative k", func() { iters.TopK(iters.List(1), -1) })
+}
+
+func ExampleSummarize() {
+	s := iters.Summarize(iters.List(2, 4, 4, 4, 5, 5, 7, 9))
+	fmt.Println(s.Count(), s.Mean(), s.StdDev())
+	// Output: 8 5 2
+}
+
+func ExampleHistogram() {
+	latencies := iters.List(3, 12, 25, 7, 120, 48)
+	fmt.Println(iters.Histogram(latencies, iters.ExponentialBuckets(10, 2, 3)))
+	// Output: [2 1 1 2]
+}
+
+func BenchmarkQuantile(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 10000)
+	for i := range values {
+		values[i] = r.Float64()
+	}
+	b.Run("Median", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			iters.Median(iters.Enumerate(values))
+		}
+	})
+	b.Run("Estimator", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			iters.Quantile(iters.Enumerate(values), 0.5)
+		}
+	})
+}
@@ -0,0 +1,423 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gopherd/core/constraints"
+)
+
+// Severity represents how severe an error is.
+type Severity int
+
+const (
+	SeverityInfo     Severity = iota // Expected errors, e.g. not found
+	SeverityWarning                  // Errors caused by the caller, e.g. invalid input
+	SeverityError                    // Errors which should be looked into
+	SeverityCritical                 // Errors which require immediate attention
+)
+
+var severityNames = [...]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(severityNames) {
+		return nil, fmt.Errorf("errkit: invalid severity %d", int(s))
+	}
+	return []byte(severityNames[s]), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severityNames {
+		if name == string(text) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("errkit: unknown severity %q", text)
+}
+
+// Status is a transport independent status of an error. The values match the
+// gRPC status codes and each of them maps to an HTTP status code.
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusCanceled
+	StatusUnknown
+	StatusInvalidArgument
+	StatusDeadlineExceeded
+	StatusNotFound
+	StatusAlreadyExists
+	StatusPermissionDenied
+	StatusResourceExhausted
+	StatusFailedPrecondition
+	StatusAborted
+	StatusOutOfRange
+	StatusUnimplemented
+	StatusInternal
+	StatusUnavailable
+	StatusDataLoss
+	StatusUnauthenticated
+)
+
+var statusInfos = [...]struct {
+	name string
+	http int
+}{
+	StatusOK:                 {"OK", http.StatusOK},
+	StatusCanceled:           {"CANCELED", 499},
+	StatusUnknown:            {"UNKNOWN", http.StatusInternalServerError},
+	StatusInvalidArgument:    {"INVALID_ARGUMENT", http.StatusBadRequest},
+	StatusDeadlineExceeded:   {"DEADLINE_EXCEEDED", http.StatusGatewayTimeout},
+	StatusNotFound:           {"NOT_FOUND", http.StatusNotFound},
+	StatusAlreadyExists:      {"ALREADY_EXISTS", http.StatusConflict},
+	StatusPermissionDenied:   {"PERMISSION_DENIED", http.StatusForbidden},
+	StatusResourceExhausted:  {"RESOURCE_EXHAUSTED", http.StatusTooManyRequests},
+	StatusFailedPrecondition: {"FAILED_PRECONDITION", http.StatusBadRequest},
+	StatusAborted:            {"ABORTED", http.StatusConflict},
+	StatusOutOfRange:         {"OUT_OF_RANGE", http.StatusBadRequest},
+	StatusUnimplemented:      {"UNIMPLEMENTED", http.StatusNotImplemented},
+	StatusInternal:           {"INTERNAL", http.StatusInternalServerError},
+	StatusUnavailable:        {"UNAVAILABLE", http.StatusServiceUnavailable},
+	StatusDataLoss:           {"DATA_LOSS", http.StatusInternalServerError},
+	StatusUnauthenticated:    {"UNAUTHENTICATED", http.StatusUnauthorized},
+}
+
+// String returns the name of the status, e.g. "NOT_FOUND".
+func (s Status) String() string {
+	if s >= 0 && int(s) < len(statusInfos) {
+		return statusInfos[s].name
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// HTTPStatus returns the HTTP status code corresponding to the status.
+func (s Status) HTTPStatus() int {
+	if s >= 0 && int(s) < len(statusInfos) {
+		return statusInfos[s].http
+	}
+	return http.StatusInternalServerError
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Status) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(statusInfos) {
+		return nil, fmt.Errorf("errkit: invalid status %d", int(s))
+	}
+	return []byte(statusInfos[s].name), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Status) UnmarshalText(text []byte) error {
+	for i, info := range statusInfos {
+		if info.name == string(text) {
+			*s = Status(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("errkit: unknown status %q", text)
+}
+
+// Range is a named range [Min, Max] of error codes, usually owned by a module.
+type Range struct {
+	Name        string
+	Min         int
+	Max         int
+	Description string `json:",omitempty"`
+}
+
+// Contains reports whether the code is in the range.
+func (r Range) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+// CodeInfo describes an error code.
+type CodeInfo struct {
+	// Code is the error code.
+	Code int
+	// Name is the unique name of the error code, e.g. "user.not_found".
+	Name string
+	// Message is the message template in text/template syntax, e.g.
+	// "user {{.id}} not found".
+	Message string
+	// Status is the transport status of the error code.
+	Status Status
+	// Severity is the severity of the error code.
+	Severity Severity
+	// Class is the retry class of the error code. If it's not set, the class is
+	// derived from Status, see RetryClass.
+	Class Class `json:",omitempty"`
+	// Range is the name of the range containing the code. It's set by Register.
+	Range string
+
+	tmpl *template.Template
+}
+
+// Format formats the message template with data.
+func (info CodeInfo) Format(data any) (string, error) {
+	if info.tmpl == nil {
+		return info.Message, nil
+	}
+	var buf bytes.Buffer
+	if err := info.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("errkit: failed to format message of code %d: %w", info.Code, err)
+	}
+	return buf.String(), nil
+}
+
+// New creates an error with the code and the message formatted with data.
+// If the message could not be formatted, the raw template is used.
+func (info CodeInfo) New(data any) Error {
+	msg, err := info.Format(data)
+	if err != nil {
+		msg = info.Message
+	}
+	return errno{no: info.Code, err: catalogError{name: info.Name, message: msg}}
+}
+
+// catalogError is the error created by CodeInfo.New.
+type catalogError struct {
+	name    string
+	message string
+}
+
+func (e catalogError) Error() string {
+	if e.message == "" {
+		return e.name
+	}
+	return e.message
+}
+
+// Catalog is a registry of error codes and code ranges.
+// It is safe for concurrent use.
+type Catalog struct {
+	mu     sync.RWMutex
+	ranges []Range
+	codes  map[int]CodeInfo
+	names  map[string]int
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		codes: make(map[int]CodeInfo),
+		names: make(map[string]int),
+	}
+}
+
+// DeclareRange declares a named range of error codes.
+// It panics if the name is already declared, the range is empty or it overlaps
+// with an existing range.
+func (c *Catalog) DeclareRange(r Range) {
+	if r.Name == "" {
+		panic("errkit: empty range name")
+	}
+	if r.Min > r.Max {
+		panic(fmt.Sprintf("errkit: invalid range %q [%d, %d]", r.Name, r.Min, r.Max))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, x := range c.ranges {
+		if x.Name == r.Name {
+			panic(fmt.Sprintf("errkit: range %q already declared", r.Name))
+		}
+		if r.Min <= x.Max && x.Min <= r.Max {
+			panic(fmt.Sprintf("errkit: range %q [%d, %d] overlaps with range %q [%d, %d]", r.Name, r.Min, r.Max, x.Name, x.Min, x.Max))
+		}
+	}
+	i, _ := slices.BinarySearchFunc(c.ranges, r.Min, func(x Range, min int) int { return x.Min - min })
+	c.ranges = slices.Insert(c.ranges, i, r)
+}
+
+// Register registers an error code. The code must be in a declared range.
+// It panics if the code or name is already registered, the code is not in
+// any declared range or the message is not a valid template.
+func (c *Catalog) Register(info CodeInfo) {
+	if info.Name == "" {
+		panic(fmt.Sprintf("errkit: empty name for code %d", info.Code))
+	}
+	if strings.Contains(info.Message, "{{") {
+		tmpl, err := template.New(info.Name).Option("missingkey=error").Parse(info.Message)
+		if err != nil {
+			panic(fmt.Sprintf("errkit: invalid message of code %d: %v", info.Code, err))
+		}
+		info.tmpl = tmpl
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.rangeOf(info.Code)
+	if !ok {
+		panic(fmt.Sprintf("errkit: code %d is not in any declared range", info.Code))
+	}
+	info.Range = r.Name
+	if old, dup := c.codes[info.Code]; dup {
+		panic(fmt.Sprintf("errkit: code %d already registered as %q", info.Code, old.Name))
+	}
+	if code, dup := c.names[info.Name]; dup {
+		panic(fmt.Sprintf("errkit: name %q already registered for code %d", info.Name, code))
+	}
+	c.codes[info.Code] = info
+	c.names[info.Name] = info.Code
+}
+
+func (c *Catalog) rangeOf(code int) (Range, bool) {
+	i, found := slices.BinarySearchFunc(c.ranges, code, func(x Range, code int) int { return x.Min - code })
+	if found {
+		return c.ranges[i], true
+	}
+	if i > 0 && c.ranges[i-1].Contains(code) {
+		return c.ranges[i-1], true
+	}
+	return Range{}, false
+}
+
+// Lookup returns the registered information of the code.
+func (c *Catalog) Lookup(code int) (CodeInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	info, ok := c.codes[code]
+	return info, ok
+}
+
+// LookupName returns the registered information of the code with the name.
+func (c *Catalog) LookupName(name string) (CodeInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	code, ok := c.names[name]
+	if !ok {
+		return CodeInfo{}, false
+	}
+	return c.codes[code], true
+}
+
+// Ranges returns the declared ranges sorted by Min.
+func (c *Catalog) Ranges() []Range {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.ranges)
+}
+
+// Codes returns the registered codes sorted by code.
+func (c *Catalog) Codes() []CodeInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	codes := make([]CodeInfo, 0, len(c.codes))
+	for _, info := range c.codes {
+		codes = append(codes, info)
+	}
+	slices.SortFunc(codes, func(a, b CodeInfo) int { return a.Code - b.Code })
+	return codes
+}
+
+// catalogJSON is the JSON representation of a catalog.
+type catalogJSON struct {
+	Ranges []Range
+	Codes  []CodeInfo
+}
+
+// WriteJSON writes the catalog as an indented JSON document.
+func (c *Catalog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(catalogJSON{Ranges: c.Ranges(), Codes: c.Codes()})
+}
+
+// WriteMarkdown writes the catalog as a Markdown document with one table of codes per range.
+func (c *Catalog) WriteMarkdown(w io.Writer) error {
+	var buf bytes.Buffer
+	codes := c.Codes()
+	buf.WriteString("# Error codes\n")
+	for _, r := range c.Ranges() {
+		fmt.Fprintf(&buf, "\n## %s (%d to %d)\n\n", r.Name, r.Min, r.Max)
+		if r.Description != "" {
+			buf.WriteString(r.Description)
+			buf.WriteString("\n\n")
+		}
+		buf.WriteString("| Code | Name | Status | HTTP | Severity | Message |\n")
+		buf.WriteString("| ---: | ---- | ------ | ---: | -------- | ------- |\n")
+		for _, info := range codes {
+			if info.Range != r.Name {
+				continue
+			}
+			fmt.Fprintf(&buf, "| %d | %s | %s | %d | %s | %s |\n",
+				info.Code, escapeMarkdown(info.Name), info.Status, info.Status.HTTPStatus(),
+				info.Severity, escapeMarkdown(info.Message))
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// DefaultCatalog is the default catalog used by the package level functions.
+// It contains the "builtin" range with EUnknown and EOK.
+var DefaultCatalog = NewCatalog()
+
+func init() {
+	DefaultCatalog.DeclareRange(Range{Name: "builtin", Min: EUnknown, Max: EOK, Description: "Built-in error codes."})
+	DefaultCatalog.Register(CodeInfo{Code: EUnknown, Name: "unknown", Message: "unknown error", Status: StatusUnknown, Severity: SeverityError})
+	DefaultCatalog.Register(CodeInfo{Code: EOK, Name: "ok", Message: "ok", Status: StatusOK, Severity: SeverityInfo})
+}
+
+// DeclareRange declares a named range of error codes in the DefaultCatalog.
+func DeclareRange(r Range) {
+	DefaultCatalog.DeclareRange(r)
+}
+
+// Register registers an error code in the DefaultCatalog.
+func Register(info CodeInfo) {
+	DefaultCatalog.Register(info)
+}
+
+// Lookup returns the information of the code registered in the DefaultCatalog.
+func Lookup[T constraints.Integer](code T) (CodeInfo, bool) {
+	return DefaultCatalog.Lookup(int(code))
+}
+
+// StatusOf returns the status of the error's code registered in the DefaultCatalog.
+// It returns StatusOK for nil and StatusUnknown if the code is not registered.
+func StatusOf(err error) Status {
+	if err == nil {
+		return StatusOK
+	}
+	if info, ok := DefaultCatalog.Lookup(Errno(err)); ok {
+		return info.Status
+	}
+	return StatusUnknown
+}
+
+// SeverityOf returns the severity of the error's code registered in the DefaultCatalog.
+// It returns SeverityError if the code is not registered.
+func SeverityOf(err error) Severity {
+	if info, ok := DefaultCatalog.Lookup(Errno(err)); ok {
+		return info.Severity
+	}
+	return SeverityError
+}
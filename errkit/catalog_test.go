@@ -0,0 +1,214 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newTestCatalog() *Catalog {
+	c := NewCatalog()
+	c.DeclareRange(Range{Name: "user", Min: 1000, Max: 1999, Description: "User service errors."})
+	c.DeclareRange(Range{Name: "order", Min: 2000, Max: 2999})
+	c.Register(CodeInfo{Code: 1001, Name: "user.not_found", Message: "user {{.id}} not found", Status: StatusNotFound, Severity: SeverityInfo})
+	c.Register(CodeInfo{Code: 1002, Name: "user.banned", Message: "user is banned | contact support", Status: StatusPermissionDenied, Severity: SeverityWarning})
+	c.Register(CodeInfo{Code: 2001, Name: "order.lost", Message: "order lost", Status: StatusDataLoss, Severity: SeverityCritical})
+	return c
+}
+
+func TestCatalogLookup(t *testing.T) {
+	c := newTestCatalog()
+	info, ok := c.Lookup(1001)
+	if !ok {
+		t.Fatalf("Expected code 1001 to be registered")
+	}
+	if info.Name != "user.not_found" || info.Range != "user" {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+	if _, ok := c.Lookup(1003); ok {
+		t.Errorf("Expected code 1003 not to be registered")
+	}
+	if info, ok := c.LookupName("order.lost"); !ok || info.Code != 2001 {
+		t.Errorf("Expected order.lost to be code 2001, got %+v", info)
+	}
+	if _, ok := c.LookupName("order.unknown"); ok {
+		t.Errorf("Expected order.unknown not to be registered")
+	}
+
+	codes := c.Codes()
+	if len(codes) != 3 || codes[0].Code != 1001 || codes[2].Code != 2001 {
+		t.Errorf("Unexpected codes: %+v", codes)
+	}
+	ranges := c.Ranges()
+	if len(ranges) != 2 || ranges[0].Name != "user" || ranges[1].Name != "order" {
+		t.Errorf("Unexpected ranges: %+v", ranges)
+	}
+}
+
+func TestCatalogPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(c *Catalog)
+	}{
+		{"empty range name", func(c *Catalog) { c.DeclareRange(Range{Min: 1, Max: 2}) }},
+		{"invalid range", func(c *Catalog) { c.DeclareRange(Range{Name: "x", Min: 2, Max: 1}) }},
+		{"duplicate range", func(c *Catalog) { c.DeclareRange(Range{Name: "user", Min: 5000, Max: 5999}) }},
+		{"overlapping range", func(c *Catalog) { c.DeclareRange(Range{Name: "x", Min: 1500, Max: 2500}) }},
+		{"code out of range", func(c *Catalog) { c.Register(CodeInfo{Code: 3000, Name: "x"}) }},
+		{"duplicate code", func(c *Catalog) { c.Register(CodeInfo{Code: 1001, Name: "x"}) }},
+		{"duplicate name", func(c *Catalog) { c.Register(CodeInfo{Code: 1003, Name: "user.banned"}) }},
+		{"empty name", func(c *Catalog) { c.Register(CodeInfo{Code: 1003}) }},
+		{"invalid template", func(c *Catalog) { c.Register(CodeInfo{Code: 1003, Name: "x", Message: "{{.id"}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCatalog()
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic")
+				}
+			}()
+			tt.fn(c)
+		})
+	}
+}
+
+func TestCodeInfoNew(t *testing.T) {
+	c := newTestCatalog()
+	info, _ := c.Lookup(1001)
+	msg, err := info.Format(map[string]any{"id": 42})
+	if err != nil || msg != "user 42 not found" {
+		t.Errorf("Format() = %q, %v, want %q", msg, err, "user 42 not found")
+	}
+	if _, err := info.Format(map[string]any{}); err == nil {
+		t.Errorf("Expected error for missing key")
+	}
+
+	err = info.New(map[string]any{"id": 7})
+	if Errno(err) != 1001 || err.Error() != "user 7 not found" {
+		t.Errorf("New() = %v (code %d)", err, Errno(err))
+	}
+	err = info.New(nil)
+	if err.Error() != info.Message {
+		t.Errorf("Expected raw message on format failure, got %q", err.Error())
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	type myErrno int
+	DeclareRange(Range{Name: "test.catalog", Min: 900000, Max: 900099})
+	Register(CodeInfo{Code: 900001, Name: "test.catalog.unavailable", Status: StatusUnavailable, Severity: SeverityCritical})
+
+	if info, ok := Lookup(myErrno(900001)); !ok || info.Status != StatusUnavailable {
+		t.Errorf("Lookup() = %+v, %v", info, ok)
+	}
+	if info, ok := Lookup(EUnknown); !ok || info.Name != "unknown" {
+		t.Errorf("Expected builtin EUnknown, got %+v, %v", info, ok)
+	}
+
+	err := New(900001, errors.New("backend down"))
+	if got := StatusOf(err); got != StatusUnavailable {
+		t.Errorf("StatusOf() = %v, want %v", got, StatusUnavailable)
+	}
+	if got := StatusOf(fmt.Errorf("wrapped: %w", err)).HTTPStatus(); got != http.StatusServiceUnavailable {
+		t.Errorf("HTTPStatus() = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if got := SeverityOf(err); got != SeverityCritical {
+		t.Errorf("SeverityOf() = %v, want %v", got, SeverityCritical)
+	}
+	if got := StatusOf(nil); got != StatusOK {
+		t.Errorf("StatusOf(nil) = %v, want %v", got, StatusOK)
+	}
+	if got := StatusOf(New(900002, errors.New("x"))); got != StatusUnknown {
+		t.Errorf("StatusOf(unregistered) = %v, want %v", got, StatusUnknown)
+	}
+	if got := SeverityOf(New(900002, errors.New("x"))); got != SeverityError {
+		t.Errorf("SeverityOf(unregistered) = %v, want %v", got, SeverityError)
+	}
+}
+
+func TestCatalogWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestCatalog().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var doc struct {
+		Ranges []Range
+		Codes  []struct {
+			Code     int
+			Name     string
+			Status   string
+			Severity string
+			Range    string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(doc.Ranges) != 2 || len(doc.Codes) != 3 {
+		t.Fatalf("Unexpected document: %s", buf.String())
+	}
+	if c := doc.Codes[0]; c.Status != "NOT_FOUND" || c.Severity != "info" || c.Range != "user" {
+		t.Errorf("Unexpected code: %+v", c)
+	}
+}
+
+func TestCatalogWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestCatalog().WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## user (1000 to 1999)",
+		"User service errors.",
+		"| 1001 | user.not_found | NOT_FOUND | 404 | info | user {{.id}} not found |",
+		`user is banned \| contact support`,
+		"## order (2000 to 2999)",
+		"| 2001 | order.lost | DATA_LOSS | 500 | critical | order lost |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestStatusAndSeverityText(t *testing.T) {
+	for s := StatusOK; s <= StatusUnauthenticated; s++ {
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d) failed: %v", s, err)
+		}
+		var got Status
+		if err := got.UnmarshalText(text); err != nil || got != s {
+			t.Errorf("UnmarshalText(%q) = %v, %v", text, got, err)
+		}
+	}
+	if _, err := Status(100).MarshalText(); err == nil {
+		t.Errorf("Expected error for invalid status")
+	}
+	if Status(100).String() != "Status(100)" || Status(100).HTTPStatus() != http.StatusInternalServerError {
+		t.Errorf("Unexpected invalid status representation")
+	}
+	for s := SeverityInfo; s <= SeverityCritical; s++ {
+		text, err := s.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d) failed: %v", s, err)
+		}
+		var got Severity
+		if err := got.UnmarshalText(text); err != nil || got != s {
+			t.Errorf("UnmarshalText(%q) = %v, %v", text, got, err)
+		}
+	}
+	var s Severity
+	if err := s.UnmarshalText([]byte("fatal")); err == nil {
+		t.Errorf("Expected error for unknown severity")
+	}
+	if Severity(-1).String() != "Severity(-1)" {
+		t.Errorf("Unexpected invalid severity representation")
+	}
+}
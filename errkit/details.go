@@ -0,0 +1,266 @@
+package errkit
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth is the maximum number of frames captured for an error.
+const maxStackDepth = 32
+
+// captureStacks reports whether New and NewWithContext capture call stacks.
+var captureStacks atomic.Bool
+
+// CaptureStacks enables or disables capturing call stacks when errors are
+// created by New, NewWithContext and With. It's disabled by default since
+// capturing a stack is relatively expensive. WithStack always captures.
+func CaptureStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// details holds the optional attributes and call stack of an errno.
+type details struct {
+	attrs []slog.Attr
+	stack []uintptr
+}
+
+// capture returns details with attrs and, if enabled, the stack starting skip
+// frames above capture's caller. It returns nil if there is nothing to hold.
+func capture(attrs []slog.Attr, skip int) *details {
+	if !captureStacks.Load() {
+		if len(attrs) == 0 {
+			return nil
+		}
+		return &details{attrs: attrs}
+	}
+	return &details{attrs: attrs, stack: callers(skip + 1)}
+}
+
+// callers returns the stack starting skip frames above callers' caller.
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return pcs[:n:n]
+}
+
+func (d *details) hasStack() bool {
+	return d != nil && len(d.stack) > 0
+}
+
+// With returns an error annotated with key/value attributes. The arguments are
+// interpreted as by slog.Logger.Log: alternating keys and values or slog.Attr
+// values. The returned error has the same code as err. It returns nil if err
+// is nil.
+func With(err error, args ...any) Error {
+	if err == nil {
+		return nil
+	}
+	attrs := slog.Group("", args...).Value.Group()
+	if e, ok := err.(errno); ok {
+		d := &details{}
+		if e.details != nil {
+			*d = *e.details
+		}
+		d.attrs = append(d.attrs[:len(d.attrs):len(d.attrs)], attrs...)
+		if !d.hasStack() && captureStacks.Load() {
+			d.stack = callers(1)
+		}
+		e.details = d
+		return e
+	}
+	return errno{no: Errno(err), err: err, details: capture(attrs, 1)}
+}
+
+// WithStack returns an error annotated with the call stack of its caller,
+// regardless of CaptureStacks. The returned error has the same code as err.
+// If err already carries a stack, it's returned unchanged. It returns nil if
+// err is nil.
+func WithStack(err error) Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(errno); ok {
+		if e.details.hasStack() {
+			return e
+		}
+		d := &details{stack: callers(1)}
+		if e.details != nil {
+			d.attrs = e.details.attrs
+		}
+		e.details = d
+		return e
+	}
+	return errno{no: Errno(err), err: err, details: &details{stack: callers(1)}}
+}
+
+// Attrs returns the attributes of all errors in err's chain, outermost first.
+func Attrs(err error) []slog.Attr {
+	var attrs []slog.Attr
+	walk(err, func(err error) {
+		if e, ok := err.(errno); ok && e.details != nil {
+			attrs = append(attrs, e.details.attrs...)
+		}
+	})
+	return attrs
+}
+
+// StackTrace returns the innermost call stack captured in err's chain, which is
+// the one closest to where the error originated.
+func StackTrace(err error) []runtime.Frame {
+	var stack []uintptr
+	walk(err, func(err error) {
+		if e, ok := err.(errno); ok && e.details.hasStack() {
+			stack = e.details.stack
+		}
+	})
+	return frames(stack)
+}
+
+// walk calls fn for err and every error in its tree in depth-first order.
+func walk(err error, fn func(error)) {
+	for err != nil {
+		fn(err)
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, child := range x.Unwrap() {
+				walk(child, fn)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// frames resolves the program counters, trimming runtime and testing frames.
+func frames(stack []uintptr) []runtime.Frame {
+	if len(stack) == 0 {
+		return nil
+	}
+	var result []runtime.Frame
+	it := runtime.CallersFrames(stack)
+	for {
+		frame, more := it.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, "testing.") {
+			result = append(result, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// LogValue implements the slog.LogValuer interface. It logs the code, the name
+// of the code if registered in the DefaultCatalog, the message, the context,
+// the attributes and the stack of the error.
+func (err errno) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs, slog.Int("code", err.no))
+	if info, ok := DefaultCatalog.Lookup(err.no); ok {
+		attrs = append(attrs, slog.String("name", info.Name))
+	}
+	attrs = append(attrs, slog.String("msg", err.Error()))
+	if err.context != "" {
+		attrs = append(attrs, slog.String("context", err.context))
+	}
+	if a := Attrs(err); len(a) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "attrs", Value: slog.GroupValue(a...)})
+	}
+	if frames := StackTrace(err); len(frames) > 0 {
+		stack := make([]string, len(frames))
+		for i, f := range frames {
+			stack[i] = fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+		}
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Format implements the fmt.Formatter interface. The %+v verb prints the full
+// cause chain with codes, attributes and stacks; other verbs print the message.
+func (err errno) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeVerbose(s, err, "")
+			return
+		}
+		io.WriteString(s, err.Error())
+	case 's':
+		io.WriteString(s, err.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", err.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(errkit.Error=%s)", verb, err.Error())
+	}
+}
+
+// writeVerbose writes err and its causes, each line prefixed with indent.
+// Plain causes with the same message as their parent are omitted.
+func writeVerbose(w io.Writer, err error, indent string) {
+	var last string
+	for first := true; err != nil; first = false {
+		msg := err.Error()
+		switch e := err.(type) {
+		case errno:
+			if !first {
+				fmt.Fprintf(w, "\n%scaused by: ", indent)
+			}
+			io.WriteString(w, msg)
+			writeDetails(w, e, indent)
+		case *MultiError:
+			if !first {
+				fmt.Fprintf(w, "\n%scaused by: ", indent)
+			}
+			fmt.Fprintf(w, "%d errors (errno %d)", len(e.errs), e.Errno())
+		default:
+			if first {
+				io.WriteString(w, msg)
+			} else if msg != last {
+				fmt.Fprintf(w, "\n%scaused by: %s", indent, msg)
+			}
+		}
+		last = msg
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for i, child := range x.Unwrap() {
+				fmt.Fprintf(w, "\n%s  [%d] ", indent, i)
+				writeVerbose(w, child, indent+"      ")
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+func writeDetails(w io.Writer, e errno, indent string) {
+	fmt.Fprintf(w, " (errno %d", e.no)
+	if info, ok := DefaultCatalog.Lookup(e.no); ok {
+		fmt.Fprintf(w, " %s", info.Name)
+	}
+	io.WriteString(w, ")")
+	if e.details == nil {
+		return
+	}
+	for _, a := range e.details.attrs {
+		fmt.Fprintf(w, "\n%s    %s", indent, a)
+	}
+	for _, f := range frames(e.details.stack) {
+		fmt.Fprintf(w, "\n%s    at %s\n%s        %s:%d", indent, f.Function, indent, f.File, f.Line)
+	}
+}
+
+var (
+	_ slog.LogValuer = errno{}
+	_ fmt.Formatter  = errno{}
+)
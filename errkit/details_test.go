@@ -0,0 +1,168 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func withStacks(t *testing.T) {
+	CaptureStacks(true)
+	t.Cleanup(func() { CaptureStacks(false) })
+}
+
+func TestCaptureStacks(t *testing.T) {
+	if frames := StackTrace(New(1, errors.New("no stack"))); frames != nil {
+		t.Errorf("Expected no stack by default, got %d frames", len(frames))
+	}
+
+	withStacks(t)
+	err := New(1, errors.New("with stack"))
+	frames := StackTrace(err)
+	if len(frames) == 0 {
+		t.Fatalf("Expected stack to be captured")
+	}
+	if want := "errkit.TestCaptureStacks"; !strings.HasSuffix(frames[0].Function, want) {
+		t.Errorf("Expected first frame to be %s, got %s", want, frames[0].Function)
+	}
+	for _, f := range frames {
+		if strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "testing.") {
+			t.Errorf("Expected runtime and testing frames to be trimmed, got %s", f.Function)
+		}
+	}
+
+	ctxErr := NewWithContext(2, err, "context")
+	if got := StackTrace(ctxErr); got[0].Line != frames[0].Line {
+		t.Errorf("Expected innermost stack at line %d, got %d", frames[0].Line, got[0].Line)
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	if WithStack(nil) != nil {
+		t.Errorf("WithStack(nil) should return nil")
+	}
+	plain := errors.New("plain")
+	err := WithStack(plain)
+	if Errno(err) != EUnknown || !errors.Is(err, plain) || err.Error() != "plain" {
+		t.Errorf("Unexpected error: %v (errno %d)", err, Errno(err))
+	}
+	if len(StackTrace(err)) == 0 {
+		t.Errorf("Expected stack regardless of CaptureStacks")
+	}
+
+	coded := With(New(42, plain), "k", "v")
+	err = WithStack(coded)
+	if Errno(err) != 42 || errors.Unwrap(err) != plain {
+		t.Errorf("Expected WithStack not to add a layer to errkit errors")
+	}
+	if len(StackTrace(err)) == 0 || len(Attrs(err)) != 1 {
+		t.Errorf("Expected stack and attrs to be kept")
+	}
+	if again := WithStack(err); StackTrace(again)[0] != StackTrace(err)[0] {
+		t.Errorf("Expected existing stack to be kept")
+	}
+}
+
+func TestWith(t *testing.T) {
+	if With(nil, "k", "v") != nil {
+		t.Errorf("With(nil) should return nil")
+	}
+	base := New(42, errors.New("base"))
+	err := With(base, "user", 7, slog.String("op", "load"))
+	err2 := With(err, "retry", true)
+	if Errno(err2) != 42 || err2.Error() != "base" {
+		t.Errorf("Unexpected error: %v (errno %d)", err2, Errno(err2))
+	}
+	if n := len(Attrs(err)); n != 2 {
+		t.Errorf("Expected With to copy attrs, got %d attrs on the original", n)
+	}
+	attrs := Attrs(fmt.Errorf("wrapped: %w", err2))
+	var keys []string
+	for _, a := range attrs {
+		keys = append(keys, a.Key)
+	}
+	if got := strings.Join(keys, ","); got != "user,op,retry" {
+		t.Errorf("Attrs() keys = %s, want user,op,retry", got)
+	}
+
+	wrapped := With(fmt.Errorf("outer: %w", base), "k", "v")
+	if Errno(wrapped) != 42 || len(Attrs(wrapped)) != 1 {
+		t.Errorf("Expected With to wrap non-errkit errors keeping the code")
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	withStacks(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := With(NewWithContext(EUnknown, errors.New("disk full"), "save user"), "user", 7)
+	logger.Error("failed", "error", err)
+
+	var record struct {
+		Error struct {
+			Code    int
+			Name    string
+			Msg     string
+			Context string
+			Attrs   map[string]any
+			Stack   []string
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unmarshal failed: %v: %s", err, buf.String())
+	}
+	e := record.Error
+	if e.Code != EUnknown || e.Name != "unknown" || e.Msg != "save user: disk full" || e.Context != "save user" {
+		t.Errorf("Unexpected log record: %s", buf.String())
+	}
+	if e.Attrs["user"] != float64(7) {
+		t.Errorf("Expected attrs in log record: %s", buf.String())
+	}
+	if len(e.Stack) == 0 || !strings.Contains(e.Stack[0], "TestLogValue") {
+		t.Errorf("Expected stack in log record: %s", buf.String())
+	}
+}
+
+func TestFormat(t *testing.T) {
+	root := errors.New("connection refused")
+	err := NewWithContext(EUnknown, With(New(7, root), "addr", "127.0.0.1"), "start")
+
+	for _, tt := range []struct {
+		format string
+		want   string
+	}{
+		{"%v", "start: connection refused"},
+		{"%s", "start: connection refused"},
+		{"%q", `"start: connection refused"`},
+		{"%d", "%!d(errkit.Error=start: connection refused)"},
+	} {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	got := fmt.Sprintf("%+v", err)
+	if strings.Count(got, "connection refused") != 2 {
+		t.Errorf("Expected causes with the same message to be omitted, got:\n%s", got)
+	}
+	for _, want := range []string{
+		"start: connection refused (errno -1 unknown)",
+		"caused by: connection refused (errno 7)\n    addr=127.0.0.1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %%+v output to contain %q, got:\n%s", want, got)
+		}
+	}
+
+	withStacks(t)
+	got = fmt.Sprintf("%+v", New(1, errors.Join(errors.New("a"), New(2, errors.New("b")))))
+	for _, want := range []string{"\n  [0] a", "\n  [1] b (errno 2)", "at github.com/gopherd/core/errkit.TestFormat"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %%+v output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
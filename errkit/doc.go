@@ -60,6 +60,48 @@ Error Code Mechanism Usage:
 		fmt.Println("User not found")
 	}
 
+6. Declare error codes in a catalog to document them for clients:
+
+	func init() {
+		errkit.DeclareRange(errkit.Range{Name: "user", Min: 1000, Max: 1999})
+		errkit.Register(errkit.CodeInfo{
+			Code:     int(ENotFound),
+			Name:     "user.not_found",
+			Message:  "user {{.id}} not found",
+			Status:   errkit.StatusNotFound,
+			Severity: errkit.SeverityInfo,
+		})
+	}
+
+	info, _ := errkit.Lookup(ENotFound)
+	err := info.New(map[string]any{"id": 42})
+	status := errkit.StatusOf(err).HTTPStatus() // 404
+
+The catalog can be exported with DefaultCatalog.WriteJSON or DefaultCatalog.WriteMarkdown.
+
+7. Attach attributes and call stacks for logging and debugging:
+
+	errkit.CaptureStacks(true) // capture stacks in New and NewWithContext
+	err := errkit.With(errkit.New(ENotFound, io.EOF), "user", id)
+	slog.Error("lookup failed", "error", err) // logs code, name, msg, attrs and stack
+	fmt.Printf("%+v\n", err)                  // prints the full cause chain
+
+8. Aggregate errors without losing their codes:
+
+	err := errkit.Join(errA, errB) // errors.Is and errors.As match errA and errB
+	code := errkit.Errno(err)      // code of the most severe error
+	fmt.Printf("%+v\n", err)       // prints the errors as a tree
+
+9. Retry operations which failed with retryable errors:
+
+	err := errkit.Retry(ctx, func(ctx context.Context) error {
+		return connect(ctx)
+	}, errkit.WithBackoff(100*time.Millisecond, 10*time.Second), errkit.WithMaxElapsed(time.Minute))
+
+Errors are classified by ClassOf: errors marked with Permanent or Temporary,
+errors implementing Retryable() or Temporary() and codes registered with a Class
+or a Status in the catalog. Retry stops on permanent errors.
+
 By using errkit, you can create more structured and easily identifiable errors
 in your Go applications, improving error handling and debugging. The error code
 mechanism allows for more detailed and type-safe error handling.
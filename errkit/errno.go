@@ -21,8 +21,10 @@ type Error interface {
 
 // errno is a struct that implements the Error interface.
 type errno struct {
-	no  int
-	err error
+	no      int
+	err     error
+	context string
+	details *details
 }
 
 // Errno returns the code of errno.
@@ -46,8 +48,9 @@ func New[T constraints.Integer](code T, err error) Error {
 		return nil
 	}
 	return errno{
-		no:  int(code),
-		err: err,
+		no:      int(code),
+		err:     err,
+		details: capture(nil, 1),
 	}
 }
 
@@ -57,8 +60,10 @@ func NewWithContext[T constraints.Integer](code T, err error, context string) Er
 		return nil
 	}
 	return errno{
-		no:  int(code),
-		err: fmt.Errorf("%s: %w", context, err),
+		no:      int(code),
+		err:     fmt.Errorf("%s: %w", context, err),
+		context: context,
+		details: capture(nil, 1),
 	}
 }
 
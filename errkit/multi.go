@@ -0,0 +1,139 @@
+package errkit
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// MultiError is an error which aggregates multiple errors, preserving the code
+// and context of each of them. errors.Is and errors.As match any of the
+// aggregated errors.
+type MultiError struct {
+	errs []error
+}
+
+// Join returns an error that aggregates the non-nil errors, flattening nested
+// MultiErrors. It returns nil if there are no non-nil errors.
+//
+// Unlike errors.Join, the returned error reports a code: the code of the most
+// severe aggregated error according to the DefaultCatalog.
+func Join(errs ...error) error {
+	var m MultiError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if x, ok := err.(*MultiError); ok {
+			m.errs = append(m.errs, x.errs...)
+		} else {
+			m.errs = append(m.errs, err)
+		}
+	}
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return &m
+}
+
+// Errors returns the aggregated errors if err is a MultiError, otherwise it
+// returns err itself as the only error. It returns nil if err is nil.
+func Errors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if m, ok := err.(*MultiError); ok {
+		return m.Errors()
+	}
+	return []error{err}
+}
+
+// Errors returns a copy of the aggregated errors.
+func (m *MultiError) Errors() []error {
+	return append([]error(nil), m.errs...)
+}
+
+// Error returns the messages of the aggregated errors separated by newlines.
+func (m *MultiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+	var sb strings.Builder
+	for i, err := range m.errs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the aggregated errors.
+func (m *MultiError) Unwrap() []error {
+	return m.errs
+}
+
+// Errno returns the code of the most severe aggregated error. If several errors
+// have the same severity, the first one wins.
+func (m *MultiError) Errno() int {
+	return Errno(m.MostSevere())
+}
+
+// MostSevere returns the most severe aggregated error according to the
+// severities registered in the DefaultCatalog.
+func (m *MultiError) MostSevere() error {
+	var (
+		result   error
+		severity Severity
+	)
+	for _, err := range m.errs {
+		if s := SeverityOf(err); result == nil || s > severity {
+			result, severity = err, s
+		}
+	}
+	return result
+}
+
+// LogValue implements the slog.LogValuer interface. It logs the code and each
+// aggregated error under its index.
+func (m *MultiError) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(m.errs)+1)
+	attrs = append(attrs, slog.Int("code", m.Errno()))
+	for i, err := range m.errs {
+		var v slog.Value
+		if _, ok := err.(slog.LogValuer); ok {
+			v = slog.AnyValue(err)
+		} else {
+			v = slog.StringValue(err.Error())
+		}
+		attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: v})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Format implements the fmt.Formatter interface. The %+v verb prints the
+// aggregated errors as a tree with their causes; other verbs print the message.
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeVerbose(s, m, "")
+			return
+		}
+		io.WriteString(s, m.Error())
+	case 's':
+		io.WriteString(s, m.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", m.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(errkit.MultiError=%s)", verb, m.Error())
+	}
+}
+
+var (
+	_ Error          = (*MultiError)(nil)
+	_ slog.LogValuer = (*MultiError)(nil)
+	_ fmt.Formatter  = (*MultiError)(nil)
+)
@@ -0,0 +1,127 @@
+package errkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func init() {
+	DeclareRange(Range{Name: "test.multi", Min: 910000, Max: 910099})
+	Register(CodeInfo{Code: 910001, Name: "test.multi.info", Severity: SeverityInfo})
+	Register(CodeInfo{Code: 910002, Name: "test.multi.critical", Severity: SeverityCritical})
+	Register(CodeInfo{Code: 910003, Name: "test.multi.warning", Severity: SeverityWarning})
+}
+
+func TestJoin(t *testing.T) {
+	if Join() != nil || Join(nil, nil) != nil {
+		t.Errorf("Join of nil errors should return nil")
+	}
+	a := New(910001, errors.New("a"))
+	b := New(910002, errors.New("b"))
+	c := errors.New("c")
+	err := Join(a, nil, Join(b, c))
+	errs := Errors(err)
+	if len(errs) != 3 || errs[0] != a || errs[1] != b || errs[2] != c {
+		t.Errorf("Expected flattened errors, got %v", errs)
+	}
+	if err.Error() != "a\nb\nc" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if Join(a).Error() != "a" {
+		t.Errorf("Expected single error message, got %q", Join(a).Error())
+	}
+	for _, target := range []error{a, b, c} {
+		if !errors.Is(err, target) {
+			t.Errorf("Expected errors.Is to match %v", target)
+		}
+	}
+	var m *MultiError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &m) || len(m.Errors()) != 3 {
+		t.Errorf("Expected errors.As to find the MultiError")
+	}
+	var coded Error
+	if !errors.As(Join(c, b), &coded) || coded.Errno() != 910002 {
+		t.Errorf("Expected errors.As to find a coded child")
+	}
+
+	if Errors(nil) != nil {
+		t.Errorf("Errors(nil) should return nil")
+	}
+	if errs := Errors(c); len(errs) != 1 || errs[0] != c {
+		t.Errorf("Errors(c) = %v", errs)
+	}
+}
+
+func TestMultiErrorErrno(t *testing.T) {
+	info := New(910001, errors.New("info"))
+	warning := New(910003, errors.New("warning"))
+	critical := New(910002, errors.New("critical"))
+	tests := []struct {
+		name string
+		errs []error
+		want int
+	}{
+		{"most severe", []error{info, critical, warning}, 910002},
+		{"first of equal severity", []error{warning, New(910003, errors.New("other"))}, 910003},
+		{"uncoded error is an error", []error{info, errors.New("plain")}, EUnknown},
+		{"nested", []error{info, fmt.Errorf("wrapped: %w", critical)}, 910002},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Join(tt.errs...)
+			if got := Errno(err); got != tt.want {
+				t.Errorf("Errno() = %d, want %d", got, tt.want)
+			}
+			if got := Errno(fmt.Errorf("outer: %w", err)); got != tt.want {
+				t.Errorf("Errno(wrapped) = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiErrorFormat(t *testing.T) {
+	err := Join(
+		NewWithContext(910001, errors.New("not found"), "load user"),
+		With(New(910002, errors.New("disk full")), "path", "/data"),
+	)
+	if got := fmt.Sprintf("%v", err); got != "load user: not found\ndisk full" {
+		t.Errorf("%%v = %q", got)
+	}
+	if got := fmt.Sprintf("%q", err); got != `"load user: not found\ndisk full"` {
+		t.Errorf("%%q = %q", got)
+	}
+	want := strings.Join([]string{
+		"2 errors (errno 910002)",
+		"  [0] load user: not found (errno 910001 test.multi.info)",
+		"      caused by: not found",
+		"  [1] disk full (errno 910002 test.multi.critical)",
+		"          path=/data",
+	}, "\n")
+	if got := fmt.Sprintf("%+v", err); got != want {
+		t.Errorf("%%+v =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMultiErrorLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failed", "error", Join(New(910001, errors.New("a")), io.EOF))
+	var record struct {
+		Error map[string]any `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if record.Error["code"] != float64(EUnknown) || record.Error["1"] != "EOF" {
+		t.Errorf("Unexpected log record: %s", buf.String())
+	}
+	if child, ok := record.Error["0"].(map[string]any); !ok || child["code"] != float64(910001) {
+		t.Errorf("Expected structured child error: %s", buf.String())
+	}
+}
@@ -0,0 +1,339 @@
+package errkit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gopherd/core/math/random"
+)
+
+// Class classifies whether an operation which failed with an error may be retried.
+type Class int
+
+const (
+	// ClassUnknown indicates that the error is not classified.
+	ClassUnknown Class = iota
+	// ClassPermanent indicates that retrying can't succeed, e.g. invalid input.
+	ClassPermanent
+	// ClassRetryable indicates that the operation may be retried as is, e.g. a
+	// conflict between concurrent transactions.
+	ClassRetryable
+	// ClassTemporary indicates a transient condition which is expected to
+	// resolve after a while, e.g. an unavailable dependency.
+	ClassTemporary
+)
+
+var classNames = [...]string{
+	ClassUnknown:   "unknown",
+	ClassPermanent: "permanent",
+	ClassRetryable: "retryable",
+	ClassTemporary: "temporary",
+}
+
+// String returns the name of the class.
+func (c Class) String() string {
+	if c >= 0 && int(c) < len(classNames) {
+		return classNames[c]
+	}
+	return fmt.Sprintf("Class(%d)", int(c))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c Class) MarshalText() ([]byte, error) {
+	if c < 0 || int(c) >= len(classNames) {
+		return nil, fmt.Errorf("errkit: invalid class %d", int(c))
+	}
+	return []byte(classNames[c]), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *Class) UnmarshalText(text []byte) error {
+	for i, name := range classNames {
+		if name == string(text) {
+			*c = Class(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("errkit: unknown class %q", text)
+}
+
+// classError marks an error with a class.
+type classError struct {
+	error
+	class Class
+}
+
+func (e classError) Unwrap() error {
+	return e.error
+}
+
+// Permanent marks err as permanent so that Retry stops retrying. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return classError{err, ClassPermanent}
+}
+
+// Temporary marks err as temporary. It returns nil if err is nil.
+func Temporary(err error) error {
+	if err == nil {
+		return nil
+	}
+	return classError{err, ClassTemporary}
+}
+
+// ClassOf classifies the error. The first of the following rules which matches
+// an error in err's chain, from the outermost error, determines the class:
+//   - errors marked by Permanent or Temporary have the marked class
+//   - errors implementing Retryable() bool are retryable or permanent
+//   - errors implementing Temporary() bool (e.g. net.Error) are temporary if it returns true
+//   - errors with a code registered in the DefaultCatalog have the class of the
+//     code, unless it's unknown
+//   - context.Canceled is permanent and context.DeadlineExceeded is temporary
+//
+// Otherwise, the class is ClassUnknown.
+func ClassOf(err error) Class {
+	class := ClassUnknown
+	walk(err, func(err error) {
+		if class != ClassUnknown {
+			return
+		}
+		switch e := err.(type) {
+		case classError:
+			class = e.class
+			return
+		case interface{ Retryable() bool }:
+			if e.Retryable() {
+				class = ClassRetryable
+			} else {
+				class = ClassPermanent
+			}
+			return
+		case interface{ Temporary() bool }:
+			if e.Temporary() {
+				class = ClassTemporary
+				return
+			}
+		}
+		if e, ok := err.(interface{ Errno() int }); ok {
+			if info, ok := DefaultCatalog.Lookup(e.Errno()); ok {
+				if class = info.RetryClass(); class != ClassUnknown {
+					return
+				}
+			}
+		}
+		switch err {
+		case context.Canceled:
+			class = ClassPermanent
+		case context.DeadlineExceeded:
+			class = ClassTemporary
+		}
+	})
+	return class
+}
+
+// IsRetryable reports whether err is classified as retryable or temporary.
+func IsRetryable(err error) bool {
+	class := ClassOf(err)
+	return class == ClassRetryable || class == ClassTemporary
+}
+
+// RetryClass returns the class of the code. If Class is not set, it's derived
+// from the status: unavailable, resource exhausted and deadline exceeded are
+// temporary, aborted is retryable, OK and unknown are unknown and other
+// statuses are permanent.
+func (info CodeInfo) RetryClass() Class {
+	if info.Class != ClassUnknown {
+		return info.Class
+	}
+	switch info.Status {
+	case StatusUnavailable, StatusResourceExhausted, StatusDeadlineExceeded:
+		return ClassTemporary
+	case StatusAborted:
+		return ClassRetryable
+	case StatusOK, StatusUnknown:
+		return ClassUnknown
+	default:
+		return ClassPermanent
+	}
+}
+
+// RetryOption represents an option for Retry.
+type RetryOption func(*retryOptions)
+
+type retryOptions struct {
+	initial     time.Duration
+	max         time.Duration
+	multiplier  float64
+	jitter      float64
+	maxElapsed  time.Duration
+	maxAttempts int
+	random      random.Uint64NGenerator
+	classify    func(error) Class
+	onRetry     func(attempt int, err error, delay time.Duration)
+}
+
+func (opts *retryOptions) apply(options []RetryOption) {
+	opts.initial = 100 * time.Millisecond
+	opts.max = 10 * time.Second
+	opts.multiplier = 2
+	opts.jitter = 0.2
+	opts.random = globalRandom{}
+	opts.classify = ClassOf
+	for _, o := range options {
+		o(opts)
+	}
+}
+
+// globalRandom generates random numbers by the math/rand top-level functions,
+// which are safe for concurrent use.
+type globalRandom struct{}
+
+func (globalRandom) Uint64N(n uint64) uint64 {
+	if n > 1<<63-1 {
+		return rand.Uint64() % n
+	}
+	return uint64(rand.Int63n(int64(n)))
+}
+
+// WithBackoff sets the delay before the first retry and the maximum delay
+// between retries. The defaults are 100ms and 10s.
+func WithBackoff(initial, max time.Duration) RetryOption {
+	return func(opts *retryOptions) {
+		opts.initial = initial
+		opts.max = max
+	}
+}
+
+// WithMultiplier sets the factor by which the delay grows after each retry.
+// The default is 2. Values less than 1 are treated as 1.
+func WithMultiplier(multiplier float64) RetryOption {
+	return func(opts *retryOptions) {
+		opts.multiplier = max(multiplier, 1)
+	}
+}
+
+// WithJitter sets the fraction in [0, 1] by which each delay is randomized: a
+// delay d becomes a random duration in [d*(1-jitter), d*(1+jitter)]. The
+// default is 0.2.
+func WithJitter(jitter float64) RetryOption {
+	return func(opts *retryOptions) {
+		opts.jitter = min(max(jitter, 0), 1)
+	}
+}
+
+// WithRandom sets the random number generator used for jitter.
+func WithRandom(r random.Uint64NGenerator) RetryOption {
+	return func(opts *retryOptions) {
+		opts.random = r
+	}
+}
+
+// WithMaxElapsed sets the maximum time spent retrying, measured from the first
+// attempt. No attempt is started after it has elapsed. Zero means no limit.
+func WithMaxElapsed(d time.Duration) RetryOption {
+	return func(opts *retryOptions) {
+		opts.maxElapsed = d
+	}
+}
+
+// WithMaxAttempts sets the maximum number of attempts, including the first one.
+// Zero means no limit.
+func WithMaxAttempts(n int) RetryOption {
+	return func(opts *retryOptions) {
+		opts.maxAttempts = n
+	}
+}
+
+// WithClassifier sets the function which classifies errors. The default is ClassOf.
+func WithClassifier(classify func(error) Class) RetryOption {
+	return func(opts *retryOptions) {
+		opts.classify = classify
+	}
+}
+
+// OnRetry sets a function called before waiting for the next attempt, e.g. to
+// log the failure.
+func OnRetry(fn func(attempt int, err error, delay time.Duration)) RetryOption {
+	return func(opts *retryOptions) {
+		opts.onRetry = fn
+	}
+}
+
+// delay returns the randomized delay for the backoff d.
+func (opts *retryOptions) delay(d time.Duration) time.Duration {
+	if opts.jitter == 0 || d <= 0 {
+		return d
+	}
+	span := time.Duration(float64(d) * opts.jitter)
+	return d - span + time.Duration(opts.random.Uint64N(uint64(2*span)+1))
+}
+
+// Retry calls fn until it succeeds, waiting with exponential backoff and jitter
+// between attempts. It stops and returns the error if the error is classified
+// as permanent, the maximum number of attempts or the maximum elapsed time is
+// reached, or the context is done. Unclassified errors are retried.
+//
+// A typical use is connecting to a dependency in a component's Start method:
+//
+//	func (c *client) Start(ctx context.Context) error {
+//		return errkit.Retry(ctx, func(ctx context.Context) error {
+//			conn, err := net.Dial("tcp", c.Options().Addr)
+//			if err != nil {
+//				return err
+//			}
+//			c.conn = conn
+//			return nil
+//		}, errkit.WithMaxElapsed(time.Minute))
+//	}
+func Retry(ctx context.Context, fn func(context.Context) error, options ...RetryOption) error {
+	_, err := RetryValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, options...)
+	return err
+}
+
+// RetryValue is like Retry but for functions which return a value.
+func RetryValue[T any](ctx context.Context, fn func(context.Context) (T, error), options ...RetryOption) (T, error) {
+	var opts retryOptions
+	opts.apply(options)
+	start := time.Now()
+	backoff := opts.initial
+	for attempt := 1; ; attempt++ {
+		v, err := fn(ctx)
+		if err == nil {
+			return v, nil
+		}
+		if ctx.Err() != nil {
+			return v, err
+		}
+		if opts.classify(err) == ClassPermanent {
+			return v, err
+		}
+		if opts.maxAttempts > 0 && attempt >= opts.maxAttempts {
+			return v, fmt.Errorf("errkit: giving up after %d attempts: %w", attempt, err)
+		}
+		delay := opts.delay(backoff)
+		if opts.maxElapsed > 0 && time.Since(start)+delay > opts.maxElapsed {
+			return v, fmt.Errorf("errkit: giving up after %v: %w", opts.maxElapsed, err)
+		}
+		if opts.onRetry != nil {
+			opts.onRetry(attempt, err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return v, Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+		if next := float64(backoff) * opts.multiplier; next < float64(opts.max) {
+			backoff = time.Duration(next)
+		} else {
+			backoff = opts.max
+		}
+	}
+}
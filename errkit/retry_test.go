@@ -0,0 +1,210 @@
+package errkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	DeclareRange(Range{Name: "test.retry", Min: 920000, Max: 920099})
+	Register(CodeInfo{Code: 920001, Name: "test.retry.unavailable", Status: StatusUnavailable})
+	Register(CodeInfo{Code: 920002, Name: "test.retry.invalid", Status: StatusInvalidArgument})
+	Register(CodeInfo{Code: 920003, Name: "test.retry.conflict", Status: StatusAlreadyExists, Class: ClassRetryable})
+}
+
+type retryableError bool
+
+func (e retryableError) Error() string   { return "retryable error" }
+func (e retryableError) Retryable() bool { return bool(e) }
+
+type temporaryError bool
+
+func (e temporaryError) Error() string   { return "temporary error" }
+func (e temporaryError) Temporary() bool { return bool(e) }
+
+func TestClassOf(t *testing.T) {
+	plain := errors.New("plain")
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, ClassUnknown},
+		{"plain", plain, ClassUnknown},
+		{"permanent mark", Permanent(New(920001, plain)), ClassPermanent},
+		{"temporary mark", fmt.Errorf("wrapped: %w", Temporary(plain)), ClassTemporary},
+		{"retryable interface", retryableError(true), ClassRetryable},
+		{"not retryable interface", retryableError(false), ClassPermanent},
+		{"temporary interface", temporaryError(true), ClassTemporary},
+		{"not temporary interface", temporaryError(false), ClassUnknown},
+		{"code from status", New(920001, plain), ClassTemporary},
+		{"permanent code", New(920002, plain), ClassPermanent},
+		{"explicit code class", New(920003, plain), ClassRetryable},
+		{"unknown code", NewWithContext(EUnknown, New(920001, plain), "context"), ClassTemporary},
+		{"unregistered code", New(920099, plain), ClassUnknown},
+		{"canceled", fmt.Errorf("op: %w", context.Canceled), ClassPermanent},
+		{"deadline exceeded", context.DeadlineExceeded, ClassTemporary},
+		{"outermost wins", Permanent(temporaryError(true)), ClassPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassOf(tt.err); got != tt.want {
+				t.Errorf("ClassOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !IsRetryable(retryableError(true)) || !IsRetryable(temporaryError(true)) || IsRetryable(plain) {
+		t.Errorf("Unexpected IsRetryable results")
+	}
+	if Permanent(nil) != nil || Temporary(nil) != nil {
+		t.Errorf("Expected marking nil to return nil")
+	}
+}
+
+func TestClassText(t *testing.T) {
+	data, err := json.Marshal(CodeInfo{Code: 1, Name: "x", Class: ClassTemporary})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"Class":"temporary"`) {
+		t.Errorf("Expected class in JSON, got %s", data)
+	}
+	var info CodeInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.Class != ClassTemporary {
+		t.Errorf("Unmarshal() = %v, %v", info.Class, err)
+	}
+	if err := info.Class.UnmarshalText([]byte("sometimes")); err == nil {
+		t.Errorf("Expected error for unknown class")
+	}
+	if Class(9).String() != "Class(9)" {
+		t.Errorf("Unexpected invalid class representation")
+	}
+}
+
+// fixedRandom always returns the largest value.
+type fixedRandom struct{}
+
+func (fixedRandom) Uint64N(n uint64) uint64 { return n - 1 }
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		var delays []time.Duration
+		attempts := 0
+		err := Retry(ctx, func(ctx context.Context) error {
+			attempts++
+			if attempts < 4 {
+				return Temporary(errors.New("not yet"))
+			}
+			return nil
+		},
+			WithBackoff(time.Millisecond, 3*time.Millisecond),
+			WithJitter(0.5),
+			WithRandom(fixedRandom{}),
+			OnRetry(func(attempt int, err error, delay time.Duration) {
+				delays = append(delays, delay)
+			}),
+		)
+		if err != nil || attempts != 4 {
+			t.Fatalf("Retry() = %v after %d attempts", err, attempts)
+		}
+		// backoffs 1ms, 2ms, 3ms (capped), randomized to the upper bound
+		want := []time.Duration{1500 * time.Microsecond, 3 * time.Millisecond, 4500 * time.Microsecond}
+		if fmt.Sprint(delays) != fmt.Sprint(want) {
+			t.Errorf("Expected delays %v, got %v", want, delays)
+		}
+	})
+
+	t.Run("stops on permanent error", func(t *testing.T) {
+		attempts := 0
+		errInvalid := New(920002, errors.New("invalid"))
+		err := Retry(ctx, func(ctx context.Context) error {
+			attempts++
+			return errInvalid
+		}, WithBackoff(time.Millisecond, time.Millisecond))
+		if err != errInvalid || attempts != 1 {
+			t.Errorf("Retry() = %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		attempts := 0
+		errUnavailable := New(920001, errors.New("unavailable"))
+		err := Retry(ctx, func(ctx context.Context) error {
+			attempts++
+			return errUnavailable
+		}, WithBackoff(time.Microsecond, time.Microsecond), WithMaxAttempts(3))
+		if !errors.Is(err, errUnavailable) || attempts != 3 || Errno(err) != 920001 {
+			t.Errorf("Retry() = %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("max elapsed", func(t *testing.T) {
+		start := time.Now()
+		err := Retry(ctx, func(ctx context.Context) error {
+			return errors.New("down")
+		}, WithBackoff(5*time.Millisecond, 5*time.Millisecond), WithJitter(0), WithMaxElapsed(30*time.Millisecond))
+		if err == nil || !strings.Contains(err.Error(), "giving up after 30ms") {
+			t.Errorf("Expected max elapsed error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected retry to stop around 30ms, took %v", elapsed)
+		}
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		errDown := errors.New("down")
+		err := Retry(ctx, func(ctx context.Context) error {
+			return errDown
+		}, WithBackoff(time.Hour, time.Hour))
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errDown) {
+			t.Errorf("Expected deadline and last error, got %v", err)
+		}
+	})
+
+	t.Run("context canceled during attempt", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		attempts := 0
+		err := Retry(ctx, func(ctx context.Context) error {
+			attempts++
+			cancel()
+			return Temporary(ctx.Err())
+		})
+		if !errors.Is(err, context.Canceled) || attempts != 1 {
+			t.Errorf("Retry() = %v after %d attempts", err, attempts)
+		}
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		attempts := 0
+		err := Retry(ctx, func(ctx context.Context) error {
+			attempts++
+			return errors.New("fatal")
+		}, WithClassifier(func(error) Class { return ClassPermanent }))
+		if err == nil || attempts != 1 {
+			t.Errorf("Retry() = %v after %d attempts", err, attempts)
+		}
+	})
+}
+
+func TestRetryValue(t *testing.T) {
+	attempts := 0
+	v, err := RetryValue(context.Background(), func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts == 1 {
+			return 0, retryableError(true)
+		}
+		return 42, nil
+	}, WithBackoff(time.Microsecond, time.Microsecond))
+	if err != nil || v != 42 {
+		t.Errorf("RetryValue() = %d, %v", v, err)
+	}
+}
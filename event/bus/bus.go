@@ -0,0 +1,546 @@
+// Package bus forwards events between the event systems of different processes
+// over stream connections such as TCP or Unix sockets, without an external broker.
+//
+// Each side of a connection subscribes to the event types it wants to receive.
+// Events dispatched to a Bus are dispatched to its local event system and
+// forwarded to every connected peer subscribed to their type. Events received
+// from a peer are dispatched to the local event system only, so they are never
+// forwarded again. Events sent over a connection are received in the order
+// they were dispatched.
+package bus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/internal/record"
+)
+
+var (
+	// ErrClosed is the error returned when using a closed Bus.
+	ErrClosed = errors.New("bus: closed")
+
+	// ErrQueueFull is the error returned when an event cannot be forwarded to a
+	// peer because its outgoing queue is full.
+	ErrQueueFull = errors.New("bus: peer queue full")
+)
+
+const maxFrameSize = event.MaxEnvelopeSize
+
+type frameKind uint8
+
+const (
+	frameSubscribe frameKind = iota + 1
+	frameUnsubscribe
+	frameEvent
+)
+
+// frame is the unit of data sent over a connection.
+type frame[T comparable] struct {
+	Kind     frameKind
+	Types    []T
+	Envelope event.Envelope
+}
+
+type options struct {
+	codec      event.Codec
+	queueSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	logger     *slog.Logger
+}
+
+// Option is a functional option for configuring a Bus.
+type Option func(*options)
+
+// apply applies the options to the given options.
+func (o *options) apply(opts []Option) {
+	o.codec = event.BinaryCodec{}
+	o.queueSize = 1024
+	o.minBackoff = 100 * time.Millisecond
+	o.maxBackoff = 10 * time.Second
+	o.logger = slog.Default()
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithCodec sets the codec used on connections. Both sides must use the same codec.
+// The default codec is event.BinaryCodec.
+func WithCodec(codec event.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithQueueSize sets the number of outgoing frames buffered per peer.
+func WithQueueSize(size int) Option {
+	return func(o *options) {
+		o.queueSize = size
+	}
+}
+
+// WithReconnectBackoff sets the minimum and maximum delay between reconnection
+// attempts of dialed connections. The delay doubles after every failed attempt.
+func WithReconnectBackoff(min, max time.Duration) Option {
+	return func(o *options) {
+		o.minBackoff = min
+		o.maxBackoff = max
+	}
+}
+
+// WithLogger sets the logger used to report connection and dispatch errors.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+type remoteKey struct{}
+
+// IsRemote reports whether the context belongs to an event received from a peer.
+func IsRemote(ctx context.Context) bool {
+	_, ok := ctx.Value(remoteKey{}).(string)
+	return ok
+}
+
+// Bus is an event.EventSystem which also exchanges events with peer processes.
+type Bus[T comparable] struct {
+	event.EventSystem[T]
+	registry *event.TypeRegistry[T]
+	options  options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	closed    bool
+	subs      map[T]struct{}
+	peers     map[*peer[T]]struct{}
+	listeners map[net.Listener]struct{}
+}
+
+// New creates a Bus which dispatches events to the local event system and
+// encodes events using the type ids of the registry.
+func New[T comparable](local event.EventSystem[T], registry *event.TypeRegistry[T], opts ...Option) *Bus[T] {
+	b := &Bus[T]{
+		EventSystem: local,
+		registry:    registry,
+		subs:        make(map[T]struct{}),
+		peers:       make(map[*peer[T]]struct{}),
+		listeners:   make(map[net.Listener]struct{}),
+	}
+	b.options.apply(opts)
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	return b
+}
+
+// Subscribe asks all current and future peers to forward events of the given types.
+func (b *Bus[T]) Subscribe(types ...T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, t := range types {
+		b.subs[t] = struct{}{}
+	}
+	b.broadcast(frame[T]{Kind: frameSubscribe, Types: types})
+}
+
+// Unsubscribe asks all peers to stop forwarding events of the given types.
+func (b *Bus[T]) Unsubscribe(types ...T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, t := range types {
+		delete(b.subs, t)
+	}
+	b.broadcast(frame[T]{Kind: frameUnsubscribe, Types: types})
+}
+
+// broadcast queues the control frame to all peers. It is called with b.mu held.
+func (b *Bus[T]) broadcast(f frame[T]) {
+	for p := range b.peers {
+		p.sendControl(f)
+	}
+}
+
+// Subscribers returns the number of connected peers subscribed to the event type.
+func (b *Bus[T]) Subscribers(t T) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for p := range b.peers {
+		if p.subscribed(t) {
+			n++
+		}
+	}
+	return n
+}
+
+// DispatchEvent implements the event.Dispatcher interface. It dispatches the event
+// to the local event system and forwards it to every subscribed peer.
+// Events are forwarded even if local listeners return errors.
+func (b *Bus[T]) DispatchEvent(ctx context.Context, e event.Event[T]) error {
+	err := b.EventSystem.DispatchEvent(ctx, e)
+	if IsRemote(ctx) {
+		return err
+	}
+	return errors.Join(err, b.forward(e))
+}
+
+func (b *Bus[T]) forward(e event.Event[T]) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	var (
+		f    *frame[T]
+		errs []error
+	)
+	for p := range b.peers {
+		if !p.subscribed(e.Typeof()) {
+			continue
+		}
+		if f == nil {
+			env, err := b.registry.Pack(b.options.codec, e)
+			if err != nil {
+				return err
+			}
+			f = &frame[T]{Kind: frameEvent, Envelope: env}
+		}
+		if !p.send(*f) {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrQueueFull, p.addr()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Serve accepts connections on the listener and exchanges events with them until
+// the listener fails or the bus is closed. It returns nil if the bus is closed.
+func (b *Bus[T]) Serve(ln net.Listener) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.listeners[ln] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.listeners, ln)
+		b.mu.Unlock()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		p, ok := b.addPeer()
+		if !ok {
+			conn.Close()
+			return nil
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer b.removePeer(p)
+			if err := p.serve(conn); err != nil && b.ctx.Err() == nil {
+				b.options.logger.Warn("bus: connection closed", "remote", conn.RemoteAddr().String(), "error", err)
+			}
+		}()
+	}
+}
+
+// Dial connects to a bus serving at the given address. The first attempt is made
+// synchronously and its error is returned. Afterwards, the connection is
+// re-established in the background whenever it breaks, until the bus is closed.
+// Events dispatched while disconnected are queued up to the queue size.
+func (b *Bus[T]) Dial(ctx context.Context, network, address string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return err
+	}
+	p, ok := b.addPeer()
+	if !ok {
+		conn.Close()
+		return ErrClosed
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer b.removePeer(p)
+		backoff := b.options.minBackoff
+		for {
+			if err := p.serve(conn); err != nil && b.ctx.Err() == nil {
+				b.options.logger.Warn("bus: connection closed", "remote", address, "error", err)
+			}
+			for {
+				select {
+				case <-b.ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				conn, err = d.DialContext(b.ctx, network, address)
+				if err == nil {
+					backoff = b.options.minBackoff
+					break
+				}
+				backoff = min(backoff*2, b.options.maxBackoff)
+			}
+		}
+	}()
+	return nil
+}
+
+// Close closes all listeners and connections and waits for their goroutines to exit.
+func (b *Bus[T]) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.cancel()
+	var errs []error
+	for ln := range b.listeners {
+		errs = append(errs, ln.Close())
+	}
+	for p := range b.peers {
+		p.close()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (b *Bus[T]) addPeer() (*peer[T], bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, false
+	}
+	p := &peer[T]{
+		bus:    b,
+		out:    make(chan frame[T], b.options.queueSize),
+		notify: make(chan struct{}, 1),
+		subs:   make(map[T]struct{}),
+	}
+	b.peers[p] = struct{}{}
+	return p, true
+}
+
+func (b *Bus[T]) removePeer(p *peer[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.peers, p)
+}
+
+// peer is the remote side of a connection. A dialed peer survives reconnections.
+type peer[T comparable] struct {
+	bus    *Bus[T]
+	out    chan frame[T]
+	notify chan struct{} // signals frames queued in control
+
+	mu      sync.Mutex
+	conn    net.Conn
+	subs    map[T]struct{} // types the remote side subscribed to
+	control []frame[T]     // subscription changes not yet written
+}
+
+func (p *peer[T]) addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return "<disconnected>"
+	}
+	return p.conn.RemoteAddr().String()
+}
+
+func (p *peer[T]) subscribed(t T) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.subs[t]
+	return ok
+}
+
+// send queues the frame without blocking and reports whether it was queued.
+func (p *peer[T]) send(f frame[T]) bool {
+	select {
+	case p.out <- f:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendControl queues a subscription change. Unlike events, subscription changes
+// are never dropped, as the remote side would otherwise keep a stale view of
+// the subscriptions until the connection is re-established. Changes made while
+// disconnected are not queued, as the subscriptions are sent on connection.
+func (p *peer[T]) sendControl(f frame[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return
+	}
+	p.control = append(p.control, f)
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// takeControl removes and returns the queued subscription changes.
+func (p *peer[T]) takeControl() []frame[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	frames := p.control
+	p.control = nil
+	return frames
+}
+
+// resync returns the frame which announces all subscriptions of the bus and
+// discards the queued subscription changes it supersedes.
+func (p *peer[T]) resync() frame[T] {
+	b := p.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	types := make([]T, 0, len(b.subs))
+	for t := range b.subs {
+		types = append(types, t)
+	}
+	p.takeControl()
+	return frame[T]{Kind: frameSubscribe, Types: types}
+}
+
+func (p *peer[T]) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+	}
+}
+
+// serve exchanges frames over the connection until it fails or the bus is closed.
+func (p *peer[T]) serve(conn net.Conn) error {
+	p.mu.Lock()
+	p.conn = conn
+	clear(p.subs)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.conn = nil
+		p.control = nil
+		p.mu.Unlock()
+		conn.Close()
+	}()
+	if p.bus.ctx.Err() != nil {
+		return nil
+	}
+
+	codec := p.bus.options.codec
+	f := p.resync()
+	if err := writeFrame(conn, codec, &f); err != nil {
+		return err
+	}
+	stop := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.writeLoop(conn, stop)
+	}()
+	err := p.readLoop(conn)
+	close(stop)
+	conn.Close()
+	if werr := <-errc; err == nil {
+		err = werr
+	}
+	if err == io.EOF || p.bus.ctx.Err() != nil {
+		err = nil
+	}
+	return err
+}
+
+func (p *peer[T]) writeLoop(conn net.Conn, stop <-chan struct{}) error {
+	codec := p.bus.options.codec
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-p.notify:
+			for _, f := range p.takeControl() {
+				if err := writeFrame(conn, codec, &f); err != nil {
+					return err
+				}
+			}
+		case f := <-p.out:
+			if err := writeFrame(conn, codec, &f); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *peer[T]) readLoop(conn net.Conn) error {
+	codec := p.bus.options.codec
+	r := bufio.NewReader(conn)
+	ctx := context.WithValue(p.bus.ctx, remoteKey{}, conn.RemoteAddr().String())
+	for {
+		var f frame[T]
+		if err := readFrame(r, codec, &f); err != nil {
+			return err
+		}
+		switch f.Kind {
+		case frameSubscribe:
+			p.mu.Lock()
+			for _, t := range f.Types {
+				p.subs[t] = struct{}{}
+			}
+			p.mu.Unlock()
+		case frameUnsubscribe:
+			p.mu.Lock()
+			for _, t := range f.Types {
+				delete(p.subs, t)
+			}
+			p.mu.Unlock()
+		case frameEvent:
+			e, err := p.bus.registry.Unpack(codec, f.Envelope)
+			if err != nil {
+				p.bus.options.logger.Warn("bus: failed to decode event", "type", f.Envelope.Type, "error", err)
+				continue
+			}
+			if err := p.bus.EventSystem.DispatchEvent(ctx, e); err != nil {
+				p.bus.options.logger.Warn("bus: failed to dispatch event", "type", f.Envelope.Type, "error", err)
+			}
+		default:
+			return fmt.Errorf("bus: unexpected frame kind %d", f.Kind)
+		}
+	}
+}
+
+func writeFrame[T comparable](w io.Writer, codec event.Codec, f *frame[T]) error {
+	data, err := codec.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return record.WriteFrame(w, data, maxFrameSize)
+}
+
+func readFrame[T comparable](r io.Reader, codec event.Codec, f *frame[T]) error {
+	data, err := record.ReadFrame(r, maxFrameSize)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, f)
+}
@@ -0,0 +1,213 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/event/bus"
+)
+
+type chatEvent struct {
+	Seq  int
+	Text string
+}
+
+func (e chatEvent) Typeof() string { return "chat" }
+
+type pingEvent struct{}
+
+func (e pingEvent) Typeof() string { return "ping" }
+
+func newRegistry() *event.TypeRegistry[string] {
+	r := event.NewTypeRegistry[string]()
+	r.Register("chat", chatEvent{})
+	r.Register("ping", pingEvent{})
+	return r
+}
+
+type collector struct {
+	mu     sync.Mutex
+	events []chatEvent
+	remote []bool
+}
+
+func (c *collector) listen(es event.EventSystem[string]) {
+	es.AddListener(event.Listen("chat", func(ctx context.Context, e chatEvent) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.events = append(c.events, e)
+		c.remote = append(c.remote, bus.IsRemote(ctx))
+		return nil
+	}))
+}
+
+func (c *collector) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.events)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBus(t *testing.T) {
+	registry := newRegistry()
+	server := bus.New(event.NewConcurrentEventSystem[string](), registry)
+	defer server.Close()
+	client := bus.New(event.NewConcurrentEventSystem[string](), registry)
+	defer client.Close()
+
+	var received collector
+	received.listen(server)
+	server.Subscribe("chat")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve(ln)
+	if err := client.Dial(context.Background(), "tcp", ln.Addr().String()); err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	waitFor(t, "subscription", func() bool { return client.Subscribers("chat") == 1 })
+	if client.Subscribers("ping") != 0 {
+		t.Errorf("Expected no subscribers for ping")
+	}
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		if err := client.DispatchEvent(context.Background(), chatEvent{Seq: i, Text: "hello"}); err != nil {
+			t.Fatalf("DispatchEvent failed: %v", err)
+		}
+		client.DispatchEvent(context.Background(), pingEvent{})
+	}
+	waitFor(t, "events", func() bool { return received.len() == n })
+	received.mu.Lock()
+	for i, e := range received.events {
+		if e.Seq != i || e.Text != "hello" || !received.remote[i] {
+			t.Errorf("Unexpected event %d: %+v (remote=%v)", i, e, received.remote[i])
+		}
+	}
+	received.mu.Unlock()
+
+	// events received from a peer are not forwarded back
+	var echoed collector
+	echoed.listen(client)
+	client.Subscribe("chat")
+	waitFor(t, "subscription", func() bool { return server.Subscribers("chat") == 1 })
+	client.DispatchEvent(context.Background(), chatEvent{Seq: n})
+	waitFor(t, "event", func() bool { return received.len() == n+1 })
+	time.Sleep(10 * time.Millisecond)
+	if echoed.len() != 1 {
+		t.Errorf("Expected only the local event on the client, got %d", echoed.len())
+	}
+
+	server.Unsubscribe("chat")
+	waitFor(t, "unsubscription", func() bool { return client.Subscribers("chat") == 0 })
+}
+
+func TestBusReconnect(t *testing.T) {
+	registry := newRegistry()
+	addr := filepath.Join(t.TempDir(), "bus.sock")
+
+	client := bus.New(event.NewEventSystem[string](false), registry, bus.WithReconnectBackoff(time.Millisecond, 10*time.Millisecond))
+	defer client.Close()
+
+	start := func(received *collector) *bus.Bus[string] {
+		server := bus.New(event.NewConcurrentEventSystem[string](), registry, bus.WithCodec(event.BinaryCodec{}))
+		received.listen(server)
+		server.Subscribe("chat")
+		ln, err := net.Listen("unix", addr)
+		if err != nil {
+			t.Fatalf("Listen failed: %v", err)
+		}
+		go server.Serve(ln)
+		return server
+	}
+
+	var first collector
+	server := start(&first)
+	if err := client.Dial(context.Background(), "unix", addr); err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	waitFor(t, "subscription", func() bool { return client.Subscribers("chat") == 1 })
+	client.DispatchEvent(context.Background(), chatEvent{Seq: 1})
+	waitFor(t, "first event", func() bool { return first.len() == 1 })
+
+	server.Close()
+
+	var second collector
+	server = start(&second)
+	defer server.Close()
+	// events dispatched while disconnected are queued until the client reconnects
+	waitFor(t, "second event", func() bool {
+		client.DispatchEvent(context.Background(), chatEvent{Seq: 2})
+		return second.len() > 0
+	})
+}
+
+func TestBusSubscribeWithFullQueue(t *testing.T) {
+	registry := newRegistry()
+	server := bus.New(event.NewEventSystem[string](false), registry, bus.WithQueueSize(1))
+	defer server.Close()
+	client := bus.New(event.NewEventSystem[string](false), registry)
+	defer client.Close()
+
+	// the client stops reading until released
+	release := make(chan struct{})
+	client.AddListener(event.Listen("chat", func(ctx context.Context, e chatEvent) error {
+		<-release
+		return nil
+	}))
+	client.Subscribe("chat")
+
+	ln, err := net.Listen("unix", filepath.Join(t.TempDir(), "bus.sock"))
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve(ln)
+	if err := client.Dial(context.Background(), "unix", ln.Addr().String()); err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	waitFor(t, "subscription", func() bool { return server.Subscribers("chat") == 1 })
+
+	text := string(make([]byte, 64<<10))
+	waitFor(t, "full queue", func() bool {
+		return errors.Is(server.DispatchEvent(context.Background(), chatEvent{Text: text}), bus.ErrQueueFull)
+	})
+	server.Subscribe("ping")
+	close(release)
+	waitFor(t, "subscription", func() bool { return client.Subscribers("ping") == 1 })
+}
+
+func TestBusClosed(t *testing.T) {
+	b := bus.New(event.NewEventSystem[string](false), newRegistry())
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	if err := b.Serve(ln); !errors.Is(err, bus.ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if err := b.DispatchEvent(context.Background(), chatEvent{}); !errors.Is(err, bus.ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package event
+
+import "github.com/gopherd/core/internal/codec"
+
+// Codec marshals and unmarshals event payloads and envelopes.
+type Codec = codec.Codec
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec = codec.JSON
+
+// GobCodec is a Codec using encoding/gob. Each value is encoded as a
+// self-describing gob stream.
+type GobCodec = codec.Gob
+
+// BinaryCodec is a compact Codec which encodes values without field names or type
+// information. Both sides must therefore agree on the exact type.
+//
+// Integers are encoded as varints, floats as fixed-size little-endian bits, and
+// strings, slices and maps are prefixed by their length. Structs are encoded as
+// their exported fields in declaration order, and pointers are prefixed by a
+// presence byte. Values implementing encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler (e.g. time.Time) are encoded as length-prefixed bytes.
+// Empty slices and maps are decoded as nil. Interfaces, channels, functions and complex
+// numbers are not supported.
+type BinaryCodec = codec.Binary
+
+// ErrUnsupportedType is the error returned when a codec cannot encode or decode a type.
+var ErrUnsupportedType = codec.ErrUnsupportedType
@@ -0,0 +1,99 @@
+package event_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/event"
+)
+
+type codecPayload struct {
+	ID       int64
+	Count    uint16
+	Ratio    float64
+	Small    float32
+	OK       bool
+	Name     string
+	Data     []byte
+	Tags     []string
+	Scores   map[string]int
+	Pos      [2]int
+	Next     *codecPayload
+	At       time.Time
+	Empty    []struct{}
+	Blanks   [][3]struct{}
+	internal int
+}
+
+func TestCodecs(t *testing.T) {
+	original := codecPayload{
+		ID:     -42,
+		Count:  7,
+		Ratio:  3.25,
+		Small:  -1.5,
+		OK:     true,
+		Name:   "player",
+		Data:   []byte{1, 2, 3},
+		Tags:   []string{"a", "b"},
+		Scores: map[string]int{"x": 1, "y": -2},
+		Pos:    [2]int{3, 4},
+		Next:   &codecPayload{Name: "next", At: time.Unix(0, 0).UTC()},
+		At:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Empty:  make([]struct{}, 3),
+		Blanks: make([][3]struct{}, 2),
+	}
+	for _, codec := range []event.Codec{event.JSONCodec{}, event.GobCodec{}, event.BinaryCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			data, err := codec.Marshal(original)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			var decoded codecPayload
+			if err := codec.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if !decoded.At.Equal(original.At) {
+				t.Errorf("Decoded time %v, want %v", decoded.At, original.At)
+			}
+			decoded.At = original.At
+			if !reflect.DeepEqual(decoded, original) {
+				t.Errorf("Decoded %+v, want %+v", decoded, original)
+			}
+		})
+	}
+}
+
+func TestBinaryCodecErrors(t *testing.T) {
+	var codec event.BinaryCodec
+	if _, err := codec.Marshal(struct{ F any }{1}); !errors.Is(err, event.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for interface field, got %v", err)
+	}
+	if _, err := codec.Marshal(nil); !errors.Is(err, event.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for nil, got %v", err)
+	}
+	var v codecPayload
+	if err := codec.Unmarshal([]byte{1}, v); !errors.Is(err, event.ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for non-pointer, got %v", err)
+	}
+	data, _ := codec.Marshal(codecPayload{Name: "truncated"})
+	if err := codec.Unmarshal(data[:len(data)-3], &v); err == nil {
+		t.Errorf("Expected error for truncated data")
+	}
+	if err := codec.Unmarshal(append(data, 0), &v); err == nil {
+		t.Errorf("Expected error for trailing data")
+	}
+}
+
+func TestBinaryCodecCompact(t *testing.T) {
+	payload := testEncodableEvent{Type: 1, Message: "hi"}
+	binary, _ := event.BinaryCodec{}.Marshal(payload)
+	json, _ := event.JSONCodec{}.Marshal(payload)
+	if len(binary) != 4 {
+		t.Errorf("Expected 4 bytes, got %d: %v", len(binary), binary)
+	}
+	if len(binary) >= len(json) {
+		t.Errorf("Expected binary encoding to be smaller than JSON: %d >= %d", len(binary), len(json))
+	}
+}
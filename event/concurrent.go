@@ -0,0 +1,509 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrQueueFull is the error returned when an event is rejected because the
+	// dispatch queue is full and the back-pressure policy is PolicyError.
+	ErrQueueFull = errors.New("event queue full")
+
+	// ErrClosed is the error returned when an event is dispatched to a closed event system.
+	ErrClosed = errors.New("event system closed")
+)
+
+// BackpressurePolicy determines how an asynchronous event system behaves when its queue is full.
+type BackpressurePolicy int
+
+const (
+	// PolicyBlock blocks the dispatcher until the queue has room or the context is done.
+	PolicyBlock BackpressurePolicy = iota
+	// PolicyDrop silently drops the event.
+	PolicyDrop
+	// PolicyError rejects the event with ErrQueueFull.
+	PolicyError
+)
+
+// String returns a string representation of the BackpressurePolicy.
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case PolicyBlock:
+		return "block"
+	case PolicyDrop:
+		return "drop"
+	case PolicyError:
+		return "error"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(p))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p BackpressurePolicy) MarshalText() ([]byte, error) {
+	switch p {
+	case PolicyBlock, PolicyDrop, PolicyError:
+		return []byte(p.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown backpressure policy %d", int(p))
+	}
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *BackpressurePolicy) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "block", "":
+		*p = PolicyBlock
+	case "drop":
+		*p = PolicyDrop
+	case "error":
+		*p = PolicyError
+	default:
+		return fmt.Errorf("unknown backpressure policy %q", text)
+	}
+	return nil
+}
+
+// PriorityListenerAdder adds a new listener with a priority and returns its ID.
+// Listeners with a higher priority are called first. Listeners with the same
+// priority are called in registration order.
+type PriorityListenerAdder[T comparable] interface {
+	AddListenerWithPriority(Listener[T], int) ListenerID
+}
+
+// ConcurrentEventSystem is an EventSystem that is safe for concurrent use by
+// multiple goroutines and can optionally dispatch events asynchronously.
+type ConcurrentEventSystem[T comparable] interface {
+	EventSystem[T]
+	PriorityListenerAdder[T]
+	CatchAllListenerAdder[T]
+	ReportDispatcher[T]
+	MiddlewareUser[T]
+
+	// Close stops accepting new events and waits until all pending events
+	// have been handled or the context is done.
+	Close(context.Context) error
+}
+
+type concurrentOptions struct {
+	async      bool
+	queueSize  int
+	workers    int
+	policy     BackpressurePolicy
+	mode       DispatchMode
+	errHandler func(context.Context, error)
+}
+
+// ConcurrentOption is a functional option for configuring a ConcurrentEventSystem.
+type ConcurrentOption func(*concurrentOptions)
+
+// apply applies the options to the given options.
+func (o *concurrentOptions) apply(opts []ConcurrentOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithAsync enables asynchronous dispatch backed by a queue of the given size
+// which is consumed by the given number of worker goroutines.
+// Events are handled in dispatch order only if workers is 1.
+func WithAsync(queueSize, workers int) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.async = true
+		o.queueSize = max(queueSize, 0)
+		o.workers = max(workers, 1)
+	}
+}
+
+// WithBackpressure sets the policy applied when the asynchronous queue is full.
+func WithBackpressure(policy BackpressurePolicy) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.policy = policy
+	}
+}
+
+// WithDispatchMode sets how errors returned by listeners affect dispatching.
+// The default mode is CollectAll.
+func WithDispatchMode(mode DispatchMode) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.mode = mode
+	}
+}
+
+// WithErrorHandler sets the function called with errors returned by listeners
+// of asynchronously dispatched events.
+func WithErrorHandler(handler func(context.Context, error)) ConcurrentOption {
+	return func(o *concurrentOptions) {
+		o.errHandler = handler
+	}
+}
+
+// CatchAllListenerAdder adds a listener that receives every event regardless of
+// its type and returns its ID. The EventType of the listener is ignored.
+type CatchAllListenerAdder[T comparable] interface {
+	AddCatchAllListener(Listener[T], int) ListenerID
+}
+
+type priorityListener[T comparable] struct {
+	id       ListenerID
+	priority int
+	listener Listener[T]
+}
+
+// before reports whether l is called before other.
+func (l priorityListener[T]) before(other priorityListener[T]) bool {
+	if l.priority != other.priority {
+		return l.priority > other.priority
+	}
+	return l.id < other.id
+}
+
+func comparePriorityListeners[T comparable](a, b priorityListener[T]) int {
+	if a.before(b) {
+		return -1
+	}
+	if b.before(a) {
+		return 1
+	}
+	return 0
+}
+
+// insertListener returns a new slice with l inserted into the sorted listeners.
+func insertListener[T comparable](listeners []priorityListener[T], l priorityListener[T]) []priorityListener[T] {
+	index, _ := slices.BinarySearchFunc(listeners, l, comparePriorityListeners[T])
+	return slices.Insert(slices.Clip(listeners), index, l)
+}
+
+// deleteListener returns a new slice without the listener with the given id.
+func deleteListener[T comparable](listeners []priorityListener[T], id ListenerID) []priorityListener[T] {
+	index := slices.IndexFunc(listeners, func(l priorityListener[T]) bool {
+		return l.id == id
+	})
+	if index < 0 {
+		return listeners
+	}
+	if len(listeners) == 1 {
+		return nil
+	}
+	return slices.Delete(slices.Clone(listeners), index, index+1)
+}
+
+// mergeListeners merges two sorted listener slices.
+func mergeListeners[T comparable](a, b []priorityListener[T]) []priorityListener[T] {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([]priorityListener[T], 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].before(a[0]) {
+			merged = append(merged, b[0])
+			b = b[1:]
+		} else {
+			merged = append(merged, a[0])
+			a = a[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// registry stores listeners by event type. All methods are called with the
+// event system's lock held. Slices returned by match must not be modified.
+type registry[T comparable] interface {
+	add(eventType T, l priorityListener[T])
+	remove(eventType T, id ListenerID)
+	match(eventType T) []priorityListener[T]
+}
+
+// exactRegistry matches listeners whose event type equals the event type.
+type exactRegistry[T comparable] map[T][]priorityListener[T] // copy-on-write, sorted
+
+func (r exactRegistry[T]) add(eventType T, l priorityListener[T]) {
+	r[eventType] = insertListener(r[eventType], l)
+}
+
+func (r exactRegistry[T]) remove(eventType T, id ListenerID) {
+	if listeners := deleteListener(r[eventType], id); len(listeners) == 0 {
+		delete(r, eventType)
+	} else {
+		r[eventType] = listeners
+	}
+}
+
+func (r exactRegistry[T]) match(eventType T) []priorityListener[T] {
+	return r[eventType]
+}
+
+type queuedEvent[T comparable] struct {
+	ctx    context.Context
+	event  Event[T]
+	report *DispatchReport
+	done   chan error // non-nil if the dispatcher waits for the result
+}
+
+type listenerKey[T comparable] struct {
+	eventType T
+	all       bool
+	listener  Listener[T]
+}
+
+type concurrentEventSystem[T comparable] struct {
+	options concurrentOptions
+
+	mu        sync.RWMutex
+	nextID    ListenerID
+	listeners registry[T]
+	all       []priorityListener[T] // catch-all listeners, copy-on-write, sorted
+	mapping   map[ListenerID]listenerKey[T]
+
+	middlewares []Middleware[T] // copy-on-write
+
+	queueMu sync.RWMutex
+	closed  bool
+	closing chan struct{} // closed when Close is called
+	senders sync.WaitGroup
+	queue   chan queuedEvent[T]
+	wg      sync.WaitGroup
+}
+
+// NewConcurrentEventSystem creates a new ConcurrentEventSystem instance.
+// By default, events are dispatched synchronously in the caller's goroutine.
+func NewConcurrentEventSystem[T comparable](opts ...ConcurrentOption) ConcurrentEventSystem[T] {
+	return newConcurrentEventSystem[T](make(exactRegistry[T]), opts)
+}
+
+func newConcurrentEventSystem[T comparable](listeners registry[T], opts []ConcurrentOption) *concurrentEventSystem[T] {
+	es := &concurrentEventSystem[T]{
+		listeners: listeners,
+		mapping:   make(map[ListenerID]listenerKey[T]),
+		closing:   make(chan struct{}),
+	}
+	es.options.apply(opts)
+	if es.options.async {
+		es.queue = make(chan queuedEvent[T], es.options.queueSize)
+		es.wg.Add(es.options.workers)
+		for i := 0; i < es.options.workers; i++ {
+			go es.work()
+		}
+	}
+	return es
+}
+
+// AddListener implements the ListenerAdder interface.
+func (es *concurrentEventSystem[T]) AddListener(listener Listener[T]) ListenerID {
+	return es.AddListenerWithPriority(listener, 0)
+}
+
+// AddListenerWithPriority implements the PriorityListenerAdder interface.
+func (es *concurrentEventSystem[T]) AddListenerWithPriority(listener Listener[T], priority int) ListenerID {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.nextID++
+	id := es.nextID
+	eventType := listener.EventType()
+	es.listeners.add(eventType, priorityListener[T]{
+		id:       id,
+		priority: priority,
+		listener: listener,
+	})
+	es.mapping[id] = listenerKey[T]{eventType: eventType, listener: listener}
+	return id
+}
+
+// AddCatchAllListener implements the CatchAllListenerAdder interface.
+func (es *concurrentEventSystem[T]) AddCatchAllListener(listener Listener[T], priority int) ListenerID {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.nextID++
+	id := es.nextID
+	es.all = insertListener(es.all, priorityListener[T]{
+		id:       id,
+		priority: priority,
+		listener: listener,
+	})
+	es.mapping[id] = listenerKey[T]{all: true, listener: listener}
+	return id
+}
+
+// RemoveListener implements the ListenerRemover interface.
+func (es *concurrentEventSystem[T]) RemoveListener(id ListenerID) bool {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	key, ok := es.mapping[id]
+	if !ok {
+		return false
+	}
+	delete(es.mapping, id)
+	if key.all {
+		es.all = deleteListener(es.all, id)
+	} else {
+		es.listeners.remove(key.eventType, id)
+	}
+	listenerRemoved(key.listener)
+	return true
+}
+
+// Use implements the MiddlewareUser interface.
+func (es *concurrentEventSystem[T]) Use(middlewares ...Middleware[T]) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	es.middlewares = append(slices.Clip(es.middlewares), middlewares...)
+}
+
+// HasListener implements the ListenerChecker interface.
+func (es *concurrentEventSystem[T]) HasListener(id ListenerID) bool {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+	_, ok := es.mapping[id]
+	return ok
+}
+
+// DispatchEvent implements the Dispatcher interface.
+// In asynchronous mode, it returns after the event is queued, and errors returned
+// by listeners are reported to the error handler.
+func (es *concurrentEventSystem[T]) DispatchEvent(ctx context.Context, event Event[T]) error {
+	if !es.options.async {
+		if es.isClosed() {
+			return ErrClosed
+		}
+		return es.handle(ctx, event, nil)
+	}
+	return es.enqueue(ctx, queuedEvent[T]{ctx: context.WithoutCancel(ctx), event: event})
+}
+
+// DispatchEventReport implements the ReportDispatcher interface.
+// In asynchronous mode, the event is queued like any other event, but the call
+// waits until the event has been handled. If the queue is full and the policy is
+// PolicyDrop, it returns ErrQueueFull instead of dropping the event silently.
+func (es *concurrentEventSystem[T]) DispatchEventReport(ctx context.Context, event Event[T]) (DispatchReport, error) {
+	var report DispatchReport
+	if !es.options.async {
+		if es.isClosed() {
+			return report, ErrClosed
+		}
+		err := es.handle(ctx, event, &report)
+		return report, err
+	}
+	done := make(chan error, 1)
+	if err := es.enqueue(ctx, queuedEvent[T]{ctx: ctx, event: event, report: &report, done: done}); err != nil {
+		return report, err
+	}
+	select {
+	case err := <-done:
+		return report, err
+	case <-ctx.Done():
+		return DispatchReport{}, ctx.Err()
+	}
+}
+
+func (es *concurrentEventSystem[T]) isClosed() bool {
+	es.queueMu.RLock()
+	defer es.queueMu.RUnlock()
+	return es.closed
+}
+
+func (es *concurrentEventSystem[T]) enqueue(ctx context.Context, item queuedEvent[T]) error {
+	// the lock is not held while sending, so that a blocked sender doesn't
+	// block Close; Close waits for the registered senders instead
+	es.queueMu.RLock()
+	if es.closed {
+		es.queueMu.RUnlock()
+		return ErrClosed
+	}
+	es.senders.Add(1)
+	es.queueMu.RUnlock()
+	defer es.senders.Done()
+
+	switch es.options.policy {
+	case PolicyDrop:
+		select {
+		case es.queue <- item:
+		default:
+			if item.done != nil {
+				// the dispatcher waits for a result which will never come
+				return ErrQueueFull
+			}
+		}
+		return nil
+	case PolicyError:
+		select {
+		case es.queue <- item:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	default:
+		select {
+		case es.queue <- item:
+			return nil
+		case <-es.closing:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (es *concurrentEventSystem[T]) work() {
+	defer es.wg.Done()
+	for item := range es.queue {
+		err := es.handle(item.ctx, item.event, item.report)
+		if item.done != nil {
+			item.done <- err
+		} else if err != nil && es.options.errHandler != nil {
+			es.options.errHandler(item.ctx, err)
+		}
+	}
+}
+
+func (es *concurrentEventSystem[T]) handle(ctx context.Context, event Event[T], report *DispatchReport) error {
+	es.mu.RLock()
+	listeners := mergeListeners(es.listeners.match(event.Typeof()), es.all)
+	middlewares := es.middlewares
+	es.mu.RUnlock()
+	if len(listeners) == 0 {
+		return nil
+	}
+	return dispatch(ctx, event, es.options.mode, middlewares, len(listeners), func(i int) (ListenerID, Listener[T]) {
+		return listeners[i].id, listeners[i].listener
+	}, report)
+}
+
+// Close implements the ConcurrentEventSystem interface.
+// Dispatchers blocked on a full queue fail with ErrClosed, while the events
+// already queued are still handled.
+func (es *concurrentEventSystem[T]) Close(ctx context.Context) error {
+	es.queueMu.Lock()
+	if es.closed {
+		es.queueMu.Unlock()
+		return nil
+	}
+	es.closed = true
+	close(es.closing)
+	es.queueMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		if es.queue != nil {
+			// no sender can be registered any more, and the registered ones
+			// return promptly now that closing is closed
+			es.senders.Wait()
+			close(es.queue)
+		}
+		es.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,266 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/event"
+)
+
+func TestConcurrentEventSystem(t *testing.T) {
+	t.Run("Priority", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		var order []int
+		add := func(n, priority int) event.ListenerID {
+			return es.AddListenerWithPriority(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				order = append(order, n)
+				return nil
+			}), priority)
+		}
+		add(1, 0)
+		add(2, 10)
+		id3 := add(3, 0)
+		add(4, -5)
+		add(5, 10)
+
+		if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := []int{2, 5, 1, 3, 4}
+		if !slices.Equal(order, want) {
+			t.Errorf("Expected call order %v, got %v", want, order)
+		}
+
+		if !es.RemoveListener(id3) {
+			t.Fatalf("Failed to remove listener")
+		}
+		if es.HasListener(id3) {
+			t.Errorf("Removed listener should not exist")
+		}
+		if es.RemoveListener(id3) {
+			t.Errorf("Should not be able to remove non-existent listener")
+		}
+		order = nil
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		want = []int{2, 5, 1, 4}
+		if !slices.Equal(order, want) {
+			t.Errorf("Expected call order %v, got %v", want, order)
+		}
+	})
+
+	t.Run("SyncError", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		expectedErr := errors.New("test error")
+		es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+			return expectedErr
+		}))
+		if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); !errors.Is(err, expectedErr) {
+			t.Errorf("Expected error %v, got %v", expectedErr, err)
+		}
+		if err := es.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); !errors.Is(err, event.ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		var called atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					id := es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+						called.Add(1)
+						return nil
+					}))
+					es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+					es.RemoveListener(id)
+				}
+			}()
+		}
+		wg.Wait()
+		if called.Load() < 800 {
+			t.Errorf("Expected at least 800 calls, got %d", called.Load())
+		}
+	})
+
+	t.Run("AsyncDrain", func(t *testing.T) {
+		var errCount atomic.Int32
+		es := event.NewConcurrentEventSystem[int](
+			event.WithAsync(16, 4),
+			event.WithErrorHandler(func(ctx context.Context, err error) {
+				errCount.Add(1)
+			}),
+		)
+		var called atomic.Int32
+		es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+			if called.Add(1)%10 == 0 {
+				return errors.New("every tenth")
+			}
+			return nil
+		}))
+		for i := 0; i < 100; i++ {
+			if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if err := es.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if n := called.Load(); n != 100 {
+			t.Errorf("Expected 100 events handled, got %d", n)
+		}
+		if errCount.Load() == 0 {
+			t.Errorf("Expected error handler to be called")
+		}
+		if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); !errors.Is(err, event.ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	})
+
+	t.Run("AsyncOrdered", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int](event.WithAsync(4, 1))
+		var order []int
+		es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+			order = append(order, len(order))
+			return nil
+		}))
+		for i := 0; i < 20; i++ {
+			es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		}
+		es.Close(context.Background())
+		if len(order) != 20 {
+			t.Errorf("Expected 20 events handled, got %d", len(order))
+		}
+	})
+
+	for _, tt := range []struct {
+		policy  event.BackpressurePolicy
+		wantErr error
+	}{
+		{event.PolicyDrop, nil},
+		{event.PolicyError, event.ErrQueueFull},
+		{event.PolicyBlock, context.DeadlineExceeded},
+	} {
+		t.Run("Backpressure/"+tt.policy.String(), func(t *testing.T) {
+			es := event.NewConcurrentEventSystem[int](event.WithAsync(1, 1), event.WithBackpressure(tt.policy))
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-release
+				return nil
+			}))
+			// first event occupies the worker, second fills the queue
+			es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+			<-started
+			es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			err := es.DispatchEvent(ctx, testEvent{eventType: 1})
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			close(release)
+			es.Close(context.Background())
+		})
+	}
+
+	t.Run("CloseTimeout", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int](event.WithAsync(1, 1))
+		release := make(chan struct{})
+		es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+			<-release
+			return nil
+		}))
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := es.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		close(release)
+	})
+
+	t.Run("CloseWithBlockedDispatchers", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int](event.WithAsync(1, 1), event.WithBackpressure(event.PolicyBlock))
+		started := make(chan struct{}, 2)
+		proceed := make(chan struct{})
+		redispatched := make(chan error, 2)
+		es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+			started <- struct{}{}
+			<-proceed
+			// blocks on the full queue until the system is closed
+			redispatched <- es.DispatchEvent(context.Background(), testEvent{eventType: 2})
+			return nil
+		}))
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		<-started
+		// fill the queue and block another producer
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		produced := make(chan error, 1)
+		go func() {
+			produced <- es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		}()
+		close(proceed)
+		// give the producer and the listener time to block on the full queue
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		closed := make(chan error, 1)
+		go func() {
+			closed <- es.Close(ctx)
+		}()
+		select {
+		case err := <-closed:
+			if err != nil {
+				t.Errorf("Expected Close to succeed, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Close did not return")
+		}
+		if err := <-produced; !errors.Is(err, event.ErrClosed) {
+			t.Errorf("Expected blocked producer to get ErrClosed, got %v", err)
+		}
+		if err := <-redispatched; !errors.Is(err, event.ErrClosed) {
+			t.Errorf("Expected re-dispatch to get ErrClosed, got %v", err)
+		}
+	})
+}
+
+func TestBackpressurePolicyText(t *testing.T) {
+	for _, p := range []event.BackpressurePolicy{event.PolicyBlock, event.PolicyDrop, event.PolicyError} {
+		text, err := p.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText failed: %v", err)
+		}
+		var got event.BackpressurePolicy
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) failed: %v", text, err)
+		}
+		if got != p {
+			t.Errorf("Expected %v, got %v", p, got)
+		}
+	}
+	var p event.BackpressurePolicy
+	if err := p.UnmarshalText([]byte("wait")); err == nil {
+		t.Errorf("Expected error for unknown policy")
+	}
+}
@@ -0,0 +1,144 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gopherd/core/internal/record"
+)
+
+// ErrUnknownEventType is the error returned when an event type is not registered
+// in a TypeRegistry.
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// MaxEnvelopeSize is the maximum size in bytes of an encoded envelope read by ReadEnvelope.
+const MaxEnvelopeSize = 64 << 20
+
+// Envelope wraps an encoded event with its type id and creation time.
+type Envelope struct {
+	// Type is the id under which the concrete event type is registered.
+	Type string
+	// Timestamp is the time at which the envelope was created.
+	Timestamp time.Time
+	// Payload is the event encoded by a Codec.
+	Payload []byte
+}
+
+// TypeRegistry maps type ids to concrete event types, so that envelopes can be
+// decoded without knowing the concrete event type in advance.
+// It is safe for concurrent use by multiple goroutines.
+type TypeRegistry[T comparable] struct {
+	mu     sync.RWMutex
+	types  map[string]reflect.Type
+	idents map[reflect.Type]string
+}
+
+// NewTypeRegistry creates a new TypeRegistry instance.
+func NewTypeRegistry[T comparable]() *TypeRegistry[T] {
+	return &TypeRegistry[T]{
+		types:  make(map[string]reflect.Type),
+		idents: make(map[reflect.Type]string),
+	}
+}
+
+// Register registers the concrete type of event with the given type id.
+// The event may be a value or a pointer; decoded events have the same form.
+// It panics if the id or the type is already registered or if event is nil.
+func (r *TypeRegistry[T]) Register(id string, event Event[T]) {
+	if event == nil {
+		panic("event: Register event " + id + " is nil")
+	}
+	t := reflect.TypeOf(event)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.types[id]; dup {
+		panic("event: Register called twice for type id " + id)
+	}
+	if prev, dup := r.idents[t]; dup {
+		panic(fmt.Sprintf("event: Register type %s twice with ids %s and %s", t, prev, id))
+	}
+	r.types[id] = t
+	r.idents[t] = id
+}
+
+// TypeID returns the type id under which the concrete type of event is registered.
+func (r *TypeRegistry[T]) TypeID(event Event[T]) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.idents[reflect.TypeOf(event)]
+	return id, ok
+}
+
+// New returns a new zero event of the type registered with the given id.
+func (r *TypeRegistry[T]) New(id string) (Event[T], error) {
+	r.mu.RLock()
+	t, ok := r.types[id]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, id)
+	}
+	if t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(Event[T]), nil
+	}
+	return reflect.Zero(t).Interface().(Event[T]), nil
+}
+
+// Pack encodes the event with the codec into an envelope stamped with the current time.
+func (r *TypeRegistry[T]) Pack(codec Codec, event Event[T]) (Envelope, error) {
+	id, ok := r.TypeID(event)
+	if !ok {
+		return Envelope{}, fmt.Errorf("%w: %T", ErrUnknownEventType, event)
+	}
+	payload, err := codec.Marshal(event)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal event %q: %w", id, err)
+	}
+	return Envelope{Type: id, Timestamp: time.Now(), Payload: payload}, nil
+}
+
+// Unpack decodes the payload of the envelope with the codec into a new event of
+// the registered type.
+func (r *TypeRegistry[T]) Unpack(codec Codec, env Envelope) (Event[T], error) {
+	r.mu.RLock()
+	t, ok := r.types[env.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, env.Type)
+	}
+	ptr := t.Kind() == reflect.Pointer
+	if ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	if err := codec.Unmarshal(env.Payload, v.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event %q: %w", env.Type, err)
+	}
+	if !ptr {
+		v = v.Elem()
+	}
+	return v.Interface().(Event[T]), nil
+}
+
+// WriteEnvelope encodes the envelope with the codec and writes it to w as a frame
+// prefixed by its length as a 4-byte big-endian integer.
+func WriteEnvelope(w io.Writer, codec Codec, env *Envelope) error {
+	data, err := codec.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return record.WriteFrame(w, data, MaxEnvelopeSize)
+}
+
+// ReadEnvelope reads a frame written by WriteEnvelope from r and decodes it with
+// the codec. It returns io.EOF if r has no more frames.
+func ReadEnvelope(r io.Reader, codec Codec, env *Envelope) error {
+	data, err := record.ReadFrame(r, MaxEnvelopeSize)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, env)
+}
@@ -0,0 +1,110 @@
+package event_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gopherd/core/event"
+)
+
+type loginEvent struct {
+	User string
+}
+
+func (e loginEvent) Typeof() string { return "user.login" }
+
+type logoutEvent struct {
+	User   string
+	Reason string
+}
+
+func (e *logoutEvent) Typeof() string { return "user.logout" }
+
+func TestTypeRegistry(t *testing.T) {
+	r := event.NewTypeRegistry[string]()
+	r.Register("login", loginEvent{})
+	r.Register("logout", &logoutEvent{})
+
+	for _, codec := range []event.Codec{event.JSONCodec{}, event.GobCodec{}, event.BinaryCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			var buf bytes.Buffer
+			for _, e := range []event.Event[string]{
+				loginEvent{User: "alice"},
+				&logoutEvent{User: "bob", Reason: "idle"},
+			} {
+				env, err := r.Pack(codec, e)
+				if err != nil {
+					t.Fatalf("Pack failed: %v", err)
+				}
+				if env.Timestamp.IsZero() {
+					t.Errorf("Expected envelope timestamp")
+				}
+				if err := event.WriteEnvelope(&buf, codec, &env); err != nil {
+					t.Fatalf("WriteEnvelope failed: %v", err)
+				}
+			}
+
+			var decoded []event.Event[string]
+			for {
+				var env event.Envelope
+				err := event.ReadEnvelope(&buf, codec, &env)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ReadEnvelope failed: %v", err)
+				}
+				e, err := r.Unpack(codec, env)
+				if err != nil {
+					t.Fatalf("Unpack failed: %v", err)
+				}
+				decoded = append(decoded, e)
+			}
+			if len(decoded) != 2 {
+				t.Fatalf("Expected 2 events, got %d", len(decoded))
+			}
+			if e, ok := decoded[0].(loginEvent); !ok || e.User != "alice" {
+				t.Errorf("Unexpected first event: %#v", decoded[0])
+			}
+			if e, ok := decoded[1].(*logoutEvent); !ok || e.User != "bob" || e.Reason != "idle" {
+				t.Errorf("Unexpected second event: %#v", decoded[1])
+			}
+		})
+	}
+}
+
+func TestTypeRegistryErrors(t *testing.T) {
+	r := event.NewTypeRegistry[string]()
+	r.Register("login", loginEvent{})
+
+	if _, err := r.Pack(event.JSONCodec{}, &logoutEvent{}); !errors.Is(err, event.ErrUnknownEventType) {
+		t.Errorf("Expected ErrUnknownEventType, got %v", err)
+	}
+	if _, err := r.Unpack(event.JSONCodec{}, event.Envelope{Type: "unknown"}); !errors.Is(err, event.ErrUnknownEventType) {
+		t.Errorf("Expected ErrUnknownEventType, got %v", err)
+	}
+	if e, err := r.New("login"); err != nil || e.Typeof() != "user.login" {
+		t.Errorf("New returned %v, %v", e, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic on duplicate registration")
+		}
+	}()
+	r.Register("login", loginEvent{})
+}
+
+func TestReadEnvelopeTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	env := event.Envelope{Type: "login", Payload: []byte("{}")}
+	if err := event.WriteEnvelope(&buf, event.JSONCodec{}, &env); err != nil {
+		t.Fatalf("WriteEnvelope failed: %v", err)
+	}
+	data := buf.Bytes()
+	if err := event.ReadEnvelope(bytes.NewReader(data[:len(data)-1]), event.JSONCodec{}, &env); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/gopherd/core/container/pair"
 )
@@ -83,23 +84,35 @@ type EventSystem[T comparable] interface {
 }
 
 type eventSystem[T comparable] struct {
-	nextID    ListenerID
-	ordered   bool
-	listeners map[T][]pair.Pair[ListenerID, Listener[T]]
-	mapping   map[ListenerID]pair.Pair[T, int]
+	nextID      ListenerID
+	ordered     bool
+	mode        DispatchMode
+	middlewares []Middleware[T] // copy-on-write
+	dispatching int             // number of dispatches in progress
+	listeners   map[T][]pair.Pair[ListenerID, Listener[T]]
+	mapping     map[ListenerID]pair.Pair[T, int]
 }
 
-func newDispatcher[T comparable](ordered bool) *eventSystem[T] {
+func newDispatcher[T comparable](ordered bool, mode DispatchMode) *eventSystem[T] {
 	return &eventSystem[T]{
 		ordered:   ordered,
+		mode:      mode,
 		listeners: make(map[T][]pair.Pair[ListenerID, Listener[T]]),
 		mapping:   make(map[ListenerID]pair.Pair[T, int]),
 	}
 }
 
-// NewEventSystem creates a new EventSystem instance.
+// NewEventSystem creates a new EventSystem instance which is not safe for
+// concurrent use. It also implements the ReportDispatcher and MiddlewareUser
+// interfaces. Listener errors are handled in the CollectAll mode.
 func NewEventSystem[T comparable](ordered bool) EventSystem[T] {
-	return newDispatcher[T](ordered)
+	return newDispatcher[T](ordered, CollectAll)
+}
+
+// NewEventSystemWithMode creates a new EventSystem instance like NewEventSystem
+// which handles listener errors in the given dispatch mode.
+func NewEventSystemWithMode[T comparable](ordered bool, mode DispatchMode) EventSystem[T] {
+	return newDispatcher[T](ordered, mode)
 }
 
 // AddListener implements the ListenerAdder interface.
@@ -115,6 +128,9 @@ func (es *eventSystem[T]) AddListener(listener Listener[T]) ListenerID {
 }
 
 // RemoveListener implements the ListenerRemover interface.
+// While an event is being dispatched, the listeners of the event type are
+// copied rather than modified in place, so a listener may remove itself or
+// others during dispatch.
 func (es *eventSystem[T]) RemoveListener(id ListenerID) bool {
 	index, ok := es.mapping[id]
 	if !ok {
@@ -122,6 +138,10 @@ func (es *eventSystem[T]) RemoveListener(id ListenerID) bool {
 	}
 	eventType := index.First
 	listeners := es.listeners[eventType]
+	listenerRemoved(listeners[index.Second].Second)
+	if es.dispatching > 0 {
+		listeners = slices.Clone(listeners)
+	}
 	last := len(listeners) - 1
 	if index.Second != last {
 		if es.ordered {
@@ -140,6 +160,11 @@ func (es *eventSystem[T]) RemoveListener(id ListenerID) bool {
 	return true
 }
 
+// Use implements the MiddlewareUser interface.
+func (es *eventSystem[T]) Use(middlewares ...Middleware[T]) {
+	es.middlewares = append(slices.Clip(es.middlewares), middlewares...)
+}
+
 // HasListener implements the Dispatcher interface.
 func (es *eventSystem[T]) HasListener(id ListenerID) bool {
 	_, ok := es.mapping[id]
@@ -147,24 +172,38 @@ func (es *eventSystem[T]) HasListener(id ListenerID) bool {
 }
 
 // DispatchEvent implements the Dispatcher interface.
+// A listener returning ErrStopPropagation prevents the event from reaching the
+// remaining listeners, and a panicking listener is reported as a *PanicError.
 func (es *eventSystem[T]) DispatchEvent(ctx context.Context, event Event[T]) error {
+	return es.dispatch(ctx, event, nil)
+}
+
+// DispatchEventReport implements the ReportDispatcher interface.
+func (es *eventSystem[T]) DispatchEventReport(ctx context.Context, event Event[T]) (DispatchReport, error) {
+	var report DispatchReport
+	err := es.dispatch(ctx, event, &report)
+	return report, err
+}
+
+func (es *eventSystem[T]) dispatch(ctx context.Context, event Event[T], report *DispatchReport) error {
 	listeners, ok := es.listeners[event.Typeof()]
 	if !ok || len(listeners) == 0 {
 		return nil
 	}
-	var errs []error
-	for i := range listeners {
-		errs = append(errs, listeners[i].Second.HandleEvent(ctx, event))
-	}
-	return errors.Join(errs...)
+	es.dispatching++
+	defer func() { es.dispatching-- }()
+	return dispatch(ctx, event, es.mode, es.middlewares, len(listeners), func(i int) (ListenerID, Listener[T]) {
+		return listeners[i].First, listeners[i].Second
+	}, report)
 }
 
-// Register registers an event type for encoding and decoding.
+// Register registers an event type for encoding and decoding with gob.
+// See TypeRegistry for decoding events of other codecs without knowing their types.
 func Register[T comparable](event Event[T]) {
 	gob.Register(event)
 }
 
-// Encoder encodes events to a writer.
+// Encoder encodes events to a writer using encoding/gob.
 type Encoder struct {
 	encoder *gob.Encoder
 }
@@ -184,7 +223,7 @@ func EncodeTo[T comparable](w io.Writer, event Event[T]) error {
 	return Encode(NewEncoder(w), event)
 }
 
-// Decoder decodes events from a reader.
+// Decoder decodes events from a reader using encoding/gob.
 type Decoder struct {
 	decoder *gob.Decoder
 }
@@ -225,6 +225,55 @@ func TestDispatcher(t *testing.T) {
 			t.Errorf("Removed listener should not exist")
 		}
 	})
+
+	for _, ordered := range []bool{true, false} {
+		t.Run(fmt.Sprintf("RemoveDuringDispatch/ordered=%v", ordered), func(t *testing.T) {
+			d := event.NewEventSystem[int](ordered)
+			var calls []int
+			var id1, id3 event.ListenerID
+			id1 = d.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				calls = append(calls, 1)
+				d.RemoveListener(id1)
+				d.RemoveListener(id3)
+				return nil
+			}))
+			d.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				calls = append(calls, 2)
+				return nil
+			}))
+			id3 = d.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				calls = append(calls, 3)
+				return nil
+			}))
+
+			// the listeners are those at the start of the dispatch
+			d.DispatchEvent(context.Background(), testEvent{eventType: 1})
+			if fmt.Sprint(calls) != "[1 2 3]" {
+				t.Errorf("Expected calls [1 2 3], got %v", calls)
+			}
+			calls = nil
+			d.DispatchEvent(context.Background(), testEvent{eventType: 1})
+			if fmt.Sprint(calls) != "[2]" {
+				t.Errorf("Expected calls [2], got %v", calls)
+			}
+		})
+	}
+
+	t.Run("RemoveAllocs", func(t *testing.T) {
+		d := event.NewEventSystem[int](true)
+		listener := event.Listen(1, func(ctx context.Context, e testEvent) error {
+			return nil
+		})
+		for i := 0; i < 100; i++ {
+			d.AddListener(listener)
+		}
+		allocs := testing.AllocsPerRun(100, func() {
+			d.RemoveListener(d.AddListener(listener))
+		})
+		if allocs != 0 {
+			t.Errorf("Expected no allocations to add and remove a listener, got %v", allocs)
+		}
+	})
 }
 
 type testEncodableEvent struct {
@@ -0,0 +1,340 @@
+// Package journal provides an append-only, segmented on-disk event log which
+// can be replayed into an event.EventSystem for crash recovery and
+// deterministic reproduction of state.
+//
+// Each record is stored as a 4-byte big-endian length, a 4-byte CRC-32
+// (Castagnoli) checksum and an event.Envelope encoded by the journal's codec.
+// Records are addressed by a monotonically increasing Offset, and segment
+// files are named after the offset of their first record.
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/internal/record"
+)
+
+var (
+	// ErrCorrupt is the error returned when a record fails its checksum or is too large.
+	ErrCorrupt = errors.New("journal: corrupt record")
+
+	// ErrClosed is the error returned when appending to a closed journal.
+	ErrClosed = errors.New("journal: closed")
+
+	// ErrTooLarge is the error returned when appending an event whose encoded
+	// envelope is larger than event.MaxEnvelopeSize.
+	ErrTooLarge = errors.New("journal: record too large")
+)
+
+// Offset is the position of a record in a journal.
+type Offset uint64
+
+// SyncPolicy determines when appended records are flushed to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncNever leaves flushing to the operating system, except on rotation and Close.
+	SyncNever SyncPolicy = iota
+	// SyncAlways flushes after every appended record.
+	SyncAlways
+	// SyncInterval flushes on append if the last flush is older than the sync interval.
+	SyncInterval
+)
+
+const (
+	segmentExt         = ".log"
+	defaultSegmentSize = 64 << 20
+)
+
+type options struct {
+	codec        event.Codec
+	segmentSize  int64
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+}
+
+// Option is a functional option for configuring a Journal or a Reader.
+type Option func(*options)
+
+// apply applies the options to the given options.
+func (o *options) apply(opts []Option) {
+	o.codec = event.BinaryCodec{}
+	o.segmentSize = defaultSegmentSize
+	o.syncInterval = time.Second
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithCodec sets the codec used to encode envelopes and events.
+// The default codec is event.BinaryCodec.
+func WithCodec(codec event.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithSegmentSize sets the size in bytes after which a new segment file is started.
+func WithSegmentSize(size int64) Option {
+	return func(o *options) {
+		o.segmentSize = size
+	}
+}
+
+// WithSyncPolicy sets the sync policy of the journal. The interval is only used
+// by SyncInterval.
+func WithSyncPolicy(policy SyncPolicy, interval time.Duration) Option {
+	return func(o *options) {
+		o.syncPolicy = policy
+		o.syncInterval = interval
+	}
+}
+
+// Journal is an append-only event log. It is safe for concurrent use by multiple goroutines.
+type Journal[T comparable] struct {
+	dir      string
+	registry *event.TypeRegistry[T]
+	options  options
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	next     Offset
+	lastSync time.Time
+	closed   bool
+}
+
+// Open opens the journal in dir, creating the directory if needed. Events are
+// encoded with the type ids of the registry. A record torn by a crash at the
+// end of the last segment is truncated, while a damaged record followed by
+// other data is reported as ErrCorrupt.
+func Open[T comparable](dir string, registry *event.TypeRegistry[T], opts ...Option) (*Journal[T], error) {
+	j := &Journal[T]{dir: dir, registry: registry}
+	j.options.apply(opts)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	bases, err := segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(bases) == 0 {
+		if err := j.createSegment(0); err != nil {
+			return nil, err
+		}
+		return j, nil
+	}
+	base := bases[len(bases)-1]
+	f, err := os.OpenFile(segmentPath(dir, base), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	n, size, err := scanSegment(f)
+	if err != nil {
+		f.Close()
+		if errors.Is(err, ErrCorrupt) {
+			err = fmt.Errorf("%w at offset %d", err, base+Offset(n))
+		}
+		return nil, err
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	j.file = f
+	j.size = size
+	j.next = base + Offset(n)
+	j.lastSync = time.Now()
+	return j, nil
+}
+
+// Dir returns the directory of the journal.
+func (j *Journal[T]) Dir() string {
+	return j.dir
+}
+
+// Next returns the offset of the next appended record.
+func (j *Journal[T]) Next() Offset {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next
+}
+
+// Append appends the event to the journal and returns its offset.
+func (j *Journal[T]) Append(e event.Event[T]) (Offset, error) {
+	env, err := j.registry.Pack(j.options.codec, e)
+	if err != nil {
+		return 0, err
+	}
+	data, err := j.options.codec.Marshal(&env)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) > event.MaxEnvelopeSize {
+		return 0, fmt.Errorf("%w: %d bytes", ErrTooLarge, len(data))
+	}
+	buf := record.Append(make([]byte, 0, record.HeaderSize+len(data)), data)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return 0, ErrClosed
+	}
+	if j.size > 0 && j.size+int64(len(buf)) > j.options.segmentSize {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := j.file.Write(buf); err != nil {
+		return 0, err
+	}
+	j.size += int64(len(buf))
+	offset := j.next
+	j.next++
+	switch j.options.syncPolicy {
+	case SyncAlways:
+		err = j.sync()
+	case SyncInterval:
+		if time.Since(j.lastSync) >= j.options.syncInterval {
+			err = j.sync()
+		}
+	}
+	return offset, err
+}
+
+// Sync flushes appended records to stable storage.
+func (j *Journal[T]) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return ErrClosed
+	}
+	return j.sync()
+}
+
+// Close flushes and closes the journal.
+func (j *Journal[T]) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	return errors.Join(j.file.Sync(), j.file.Close())
+}
+
+func (j *Journal[T]) sync() error {
+	j.lastSync = time.Now()
+	return j.file.Sync()
+}
+
+func (j *Journal[T]) rotate() error {
+	if err := j.file.Sync(); err != nil {
+		return err
+	}
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+	return j.createSegment(j.next)
+}
+
+func (j *Journal[T]) createSegment(base Offset) error {
+	f, err := os.OpenFile(segmentPath(j.dir, base), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	j.file = f
+	j.size = 0
+	j.lastSync = time.Now()
+	return nil
+}
+
+func segmentPath(dir string, base Offset) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", base, segmentExt))
+}
+
+// segments returns the base offsets of the segments in dir in ascending order.
+func segments(dir string) ([]Offset, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var bases []Offset
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		bases = append(bases, Offset(base))
+	}
+	slices.Sort(bases)
+	return bases, nil
+}
+
+// readRecord reads the next record from r. It returns io.EOF at a clean end of
+// the segment and io.ErrUnexpectedEOF or ErrCorrupt for a torn or damaged record.
+func readRecord(r io.Reader) ([]byte, error) {
+	data, err := record.Read(r, event.MaxEnvelopeSize)
+	if err == record.ErrCorrupt {
+		err = ErrCorrupt
+	}
+	return data, err
+}
+
+// scanSegment returns the number of valid records at the beginning of the
+// segment and their total size in bytes. A damaged record is only accepted as
+// the end of the segment if it extends to the end of the file, i.e. if it is
+// the last record and was torn by a crash; otherwise ErrCorrupt is returned
+// along with the number of records before it.
+func scanSegment(f *os.File) (n int, size int64, err error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	r := bufio.NewReader(f)
+	for {
+		data, err := readRecord(r)
+		switch err {
+		case nil:
+			n++
+			size += int64(record.HeaderSize + len(data))
+		case io.EOF, io.ErrUnexpectedEOF:
+			return n, size, nil
+		case ErrCorrupt:
+			var header [4]byte
+			if _, err := f.ReadAt(header[:], size); err != nil {
+				return n, size, err
+			}
+			end := size + record.HeaderSize + int64(binary.BigEndian.Uint32(header[:]))
+			if end >= info.Size() {
+				return n, size, nil
+			}
+			return n, size, ErrCorrupt
+		default:
+			return 0, 0, err
+		}
+	}
+}
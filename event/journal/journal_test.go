@@ -0,0 +1,317 @@
+package journal_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/event/journal"
+)
+
+type scoreEvent struct {
+	Player string
+	Delta  int
+}
+
+func (e scoreEvent) Typeof() string { return "score" }
+
+func newRegistry() *event.TypeRegistry[string] {
+	r := event.NewTypeRegistry[string]()
+	r.Register("score", scoreEvent{})
+	return r
+}
+
+func appendScores(t *testing.T, j *journal.Journal[string], from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		offset, err := j.Append(scoreEvent{Player: "p", Delta: i})
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if offset != journal.Offset(i) {
+			t.Fatalf("Append returned offset %d, want %d", offset, i)
+		}
+	}
+}
+
+func TestJournalAppendAndRead(t *testing.T) {
+	for _, codec := range []event.Codec{event.BinaryCodec{}, event.JSONCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			dir := t.TempDir()
+			registry := newRegistry()
+			j, err := journal.Open(dir, registry,
+				journal.WithCodec(codec),
+				journal.WithSegmentSize(128),
+				journal.WithSyncPolicy(journal.SyncAlways, 0),
+			)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			appendScores(t, j, 0, 20)
+			if err := j.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			if _, err := j.Append(scoreEvent{}); !errors.Is(err, journal.ErrClosed) {
+				t.Errorf("Expected ErrClosed, got %v", err)
+			}
+
+			entries, _ := os.ReadDir(dir)
+			if len(entries) < 2 {
+				t.Errorf("Expected multiple segments, got %d", len(entries))
+			}
+
+			r, err := journal.NewReader(dir, registry, 7, journal.WithCodec(codec))
+			if err != nil {
+				t.Fatalf("NewReader failed: %v", err)
+			}
+			defer r.Close()
+			for i := 7; i < 20; i++ {
+				rec, err := r.Next()
+				if err != nil {
+					t.Fatalf("Next failed at %d: %v", i, err)
+				}
+				if rec.Offset != journal.Offset(i) || rec.Event.(scoreEvent).Delta != i {
+					t.Errorf("Unexpected record %+v at %d", rec, i)
+				}
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("Expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJournalRecovery(t *testing.T) {
+	dir := t.TempDir()
+	registry := newRegistry()
+	j, err := journal.Open(dir, registry)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	appendScores(t, j, 0, 5)
+	j.Close()
+
+	// simulate a record torn by a crash
+	entries, _ := os.ReadDir(dir)
+	path := filepath.Join(dir, entries[len(entries)-1].Name())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	f.Write([]byte{0, 0, 0, 100, 1, 2, 3})
+	f.Close()
+
+	j, err = journal.Open(dir, registry)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if next := j.Next(); next != 5 {
+		t.Errorf("Expected next offset 5 after recovery, got %d", next)
+	}
+	appendScores(t, j, 5, 8)
+	j.Close()
+
+	var sum int
+	es := event.NewEventSystem[string](true)
+	es.AddListener(event.Listen("score", func(ctx context.Context, e scoreEvent) error {
+		if !journal.IsReplay(ctx) {
+			t.Errorf("Expected replay context")
+		}
+		sum += e.Delta
+		return nil
+	}))
+	next, err := journal.Replay(context.Background(), dir, registry, 0, es)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if next != 8 || sum != 0+1+2+3+4+5+6+7 {
+		t.Errorf("Replay returned next=%d sum=%d", next, sum)
+	}
+}
+
+func TestReaderTail(t *testing.T) {
+	registry := newRegistry()
+
+	// encode a single record to append by hand
+	raw := t.TempDir()
+	j, _ := journal.Open(raw, registry)
+	appendScores(t, j, 0, 1)
+	j.Close()
+	entries, _ := os.ReadDir(raw)
+	record, err := os.ReadFile(filepath.Join(raw, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	j, _ = journal.Open(dir, registry, journal.WithSegmentSize(int64(len(record))))
+	j.Close()
+	r, err := journal.NewReader(dir, registry, 0)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	defer r.Close()
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+
+	// append the record in two halves
+	entries, _ = os.ReadDir(dir)
+	path := filepath.Join(dir, entries[0].Name())
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	for _, half := range [][]byte{record[:len(record)/2], record[len(record)/2:]} {
+		if _, err := r.Next(); err != io.EOF {
+			t.Fatalf("Expected io.EOF, got %v", err)
+		}
+		f.Write(half)
+	}
+	f.Close()
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	if rec.Offset != 0 || rec.Event.(scoreEvent).Delta != 0 {
+		t.Errorf("Unexpected record %+v", rec)
+	}
+
+	// records of segments created by rotation after the reader was opened
+	j, err = journal.Open(dir, registry, journal.WithSegmentSize(int64(len(record))))
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	appendScores(t, j, 1, 4)
+	j.Close()
+	if entries, _ := os.ReadDir(dir); len(entries) != 4 {
+		t.Fatalf("Expected 4 segments, got %d", len(entries))
+	}
+	for i := 1; i < 4; i++ {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next failed at %d: %v", i, err)
+		}
+		if rec.Offset != journal.Offset(i) || rec.Event.(scoreEvent).Delta != i {
+			t.Errorf("Unexpected record %+v at %d", rec, i)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestJournalCorruption(t *testing.T) {
+	dir := t.TempDir()
+	registry := newRegistry()
+	j, _ := journal.Open(dir, registry, journal.WithSegmentSize(64))
+	appendScores(t, j, 0, 10)
+	j.Close()
+
+	entries, _ := os.ReadDir(dir)
+	path := filepath.Join(dir, entries[0].Name())
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xFF
+	os.WriteFile(path, data, 0o644)
+
+	_, err := journal.Replay(context.Background(), dir, registry, 0, event.NewEventSystem[string](false))
+	if !errors.Is(err, journal.ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestJournalOpenCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	registry := newRegistry()
+	j, _ := journal.Open(dir, registry)
+	appendScores(t, j, 0, 1)
+	if _, err := j.Append(scoreEvent{Player: strings.Repeat("p", event.MaxEnvelopeSize)}); !errors.Is(err, journal.ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if next := j.Next(); next != 1 {
+		t.Errorf("Expected next offset 1 after a rejected append, got %d", next)
+	}
+	j.Close()
+
+	entries, _ := os.ReadDir(dir)
+	path := filepath.Join(dir, entries[0].Name())
+	info, _ := os.Stat(path)
+	first := info.Size()
+
+	j, err := journal.Open(dir, registry)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	appendScores(t, j, 1, 3)
+	j.Close()
+
+	// a damaged last record is truncated like a torn one
+	data, _ := os.ReadFile(path)
+	damaged := slices.Clone(data)
+	damaged[len(damaged)-1] ^= 0xFF
+	os.WriteFile(path, damaged, 0o644)
+	j, err = journal.Open(dir, registry)
+	if err != nil {
+		t.Fatalf("Open with a damaged last record failed: %v", err)
+	}
+	if next := j.Next(); next != 2 {
+		t.Errorf("Expected next offset 2, got %d", next)
+	}
+	j.Close()
+
+	// a damaged record followed by valid ones must not be truncated
+	damaged = slices.Clone(data)
+	damaged[first+8] ^= 0xFF
+	os.WriteFile(path, damaged, 0o644)
+	if _, err := journal.Open(dir, registry); !errors.Is(err, journal.ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != int64(len(data)) {
+		t.Errorf("Expected segment size %d to be kept, got %d", len(data), info.Size())
+	}
+}
+
+func TestTee(t *testing.T) {
+	dir := t.TempDir()
+	registry := newRegistry()
+	j, _ := journal.Open(dir, registry)
+	defer j.Close()
+
+	var live int
+	es := journal.Tee(event.NewEventSystem[string](true), j)
+	es.AddListener(event.Listen("score", func(ctx context.Context, e scoreEvent) error {
+		live += e.Delta
+		return nil
+	}))
+	for i := 1; i <= 3; i++ {
+		if err := es.DispatchEvent(context.Background(), scoreEvent{Delta: i}); err != nil {
+			t.Fatalf("DispatchEvent failed: %v", err)
+		}
+	}
+	if err := j.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// replaying into the tee must not append the events again
+	var replayed int
+	restored := journal.Tee(event.NewEventSystem[string](true), j)
+	restored.AddListener(event.Listen("score", func(ctx context.Context, e scoreEvent) error {
+		replayed += e.Delta
+		return nil
+	}))
+	if _, err := journal.Replay(context.Background(), dir, registry, 0, restored); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if live != 6 || replayed != live {
+		t.Errorf("Expected replayed state %d to equal live state %d", replayed, live)
+	}
+	if next := j.Next(); next != 3 {
+		t.Errorf("Expected 3 records, got %d", next)
+	}
+}
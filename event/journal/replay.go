@@ -0,0 +1,269 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/gopherd/core/event"
+	"github.com/gopherd/core/internal/record"
+)
+
+// Record is an event read from a journal.
+type Record[T comparable] struct {
+	// Offset is the offset of the record in the journal.
+	Offset Offset
+	// Envelope is the decoded envelope of the record.
+	Envelope event.Envelope
+	// Event is the event decoded from the envelope payload.
+	Event event.Event[T]
+}
+
+// Reader reads records from the segments of a journal directory in order.
+// A Reader may be used while the journal is being appended to; it reads the
+// records that are present when it reaches them, including those of segments
+// created after the Reader was opened. A record which is only partially
+// written is reported as io.EOF and read again by the next call to Next.
+type Reader[T comparable] struct {
+	dir      string
+	registry *event.TypeRegistry[T]
+	options  options
+
+	bases  []Offset
+	index  int // index of the current segment in bases
+	file   *os.File
+	reader *bufio.Reader
+	pos    int64 // position of the next record in the current segment
+	next   Offset
+}
+
+// NewReader creates a Reader for the journal in dir which starts at the given offset.
+// The codec option must match the one the journal was written with.
+func NewReader[T comparable](dir string, registry *event.TypeRegistry[T], from Offset, opts ...Option) (*Reader[T], error) {
+	r := &Reader[T]{dir: dir, registry: registry}
+	r.options.apply(opts)
+	bases, err := segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	r.bases = bases
+	// find the last segment starting at or before the offset
+	r.index = -1
+	for i, base := range bases {
+		if base <= from {
+			r.index = i
+		}
+	}
+	if r.index < 0 {
+		if len(bases) > 0 {
+			return nil, fmt.Errorf("journal: offset %d precedes first segment %d", from, bases[0])
+		}
+		return r, nil
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	for r.next < from {
+		if _, err := r.readNext(); err != nil {
+			r.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("journal: offset %d beyond end of journal", from)
+			}
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Next reads the next record. It returns io.EOF if there are no more records.
+func (r *Reader[T]) Next() (Record[T], error) {
+	offset := r.next
+	data, err := r.readNext()
+	if err != nil {
+		return Record[T]{}, err
+	}
+	rec := Record[T]{Offset: offset}
+	if err := r.options.codec.Unmarshal(data, &rec.Envelope); err != nil {
+		return Record[T]{}, fmt.Errorf("journal: failed to decode record %d: %w", offset, err)
+	}
+	if rec.Event, err = r.registry.Unpack(r.options.codec, rec.Envelope); err != nil {
+		return Record[T]{}, fmt.Errorf("journal: failed to decode record %d: %w", offset, err)
+	}
+	return rec, nil
+}
+
+// Close closes the reader.
+func (r *Reader[T]) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *Reader[T]) open() error {
+	f, err := os.Open(segmentPath(r.dir, r.bases[r.index]))
+	if err != nil {
+		return err
+	}
+	r.file = f
+	r.reader = bufio.NewReader(f)
+	r.pos = 0
+	r.next = r.bases[r.index]
+	return nil
+}
+
+// rewind moves the reader back to the start of the record being read, so a
+// record which is still being appended is read again from its header.
+func (r *Reader[T]) rewind() error {
+	if _, err := r.file.Seek(r.pos, io.SeekStart); err != nil {
+		return err
+	}
+	r.reader.Reset(r.file)
+	return nil
+}
+
+// refresh lists the segments again to find those created by rotation after
+// the reader was opened.
+func (r *Reader[T]) refresh() error {
+	bases, err := segments(r.dir)
+	if err != nil {
+		return err
+	}
+	index := slices.Index(bases, r.bases[r.index])
+	if index < 0 {
+		return fmt.Errorf("journal: segment %d was removed while reading", r.bases[r.index])
+	}
+	r.bases = bases
+	r.index = index
+	return nil
+}
+
+// readNext reads the raw data of the next record, moving to the next segment at
+// the end of the current one.
+func (r *Reader[T]) readNext() ([]byte, error) {
+	for {
+		if r.file == nil {
+			return nil, io.EOF
+		}
+		data, err := readRecord(r.reader)
+		if err == nil {
+			r.pos += int64(record.HeaderSize + len(data))
+			r.next++
+			return data, nil
+		}
+		if err == io.EOF && r.index+1 == len(r.bases) {
+			// the journal may have been rotated since the segments were listed
+			if err := r.refresh(); err != nil {
+				return nil, err
+			}
+			if r.index+1 == len(r.bases) {
+				return nil, io.EOF
+			}
+			// the segment may have been appended to before the rotation
+			continue
+		}
+		if err == io.EOF {
+			if err := r.Close(); err != nil {
+				return nil, err
+			}
+			r.index++
+			if err := r.open(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err == io.ErrUnexpectedEOF && r.index+1 == len(r.bases) {
+			// a record of the last segment is being written or was torn by a crash
+			if err := r.rewind(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		if err == io.ErrUnexpectedEOF {
+			err = ErrCorrupt
+		}
+		if errors.Is(err, ErrCorrupt) {
+			err = fmt.Errorf("%w at offset %d", err, r.next)
+		}
+		return nil, err
+	}
+}
+
+type replayKey struct{}
+
+// IsReplay reports whether the context belongs to an event dispatched by Replay.
+// Listeners can use it to skip external side effects during recovery.
+func IsReplay(ctx context.Context) bool {
+	_, ok := ctx.Value(replayKey{}).(Offset)
+	return ok
+}
+
+// ReplayOffset returns the journal offset of the event being replayed.
+func ReplayOffset(ctx context.Context) (Offset, bool) {
+	offset, ok := ctx.Value(replayKey{}).(Offset)
+	return offset, ok
+}
+
+// Replay reads the journal in dir from the given offset and dispatches every
+// event to the dispatcher in order. It returns the offset following the last
+// dispatched event, which can be used to resume replaying later.
+// Replay stops at the first dispatch error or when the context is done.
+func Replay[T comparable](ctx context.Context, dir string, registry *event.TypeRegistry[T], from Offset, d event.Dispatcher[T], opts ...Option) (Offset, error) {
+	r, err := NewReader(dir, registry, from, opts...)
+	if err != nil {
+		return from, err
+	}
+	defer r.Close()
+	next := from
+	for {
+		if err := ctx.Err(); err != nil {
+			return next, err
+		}
+		rec, err := r.Next()
+		if err == io.EOF {
+			return next, nil
+		}
+		if err != nil {
+			return next, err
+		}
+		if err := d.DispatchEvent(context.WithValue(ctx, replayKey{}, rec.Offset), rec.Event); err != nil {
+			return next, fmt.Errorf("journal: failed to replay record %d: %w", rec.Offset, err)
+		}
+		next = rec.Offset + 1
+	}
+}
+
+// TeeEventSystem is an event.EventSystem which appends every dispatched event to
+// a journal before dispatching it.
+type TeeEventSystem[T comparable] struct {
+	event.EventSystem[T]
+	journal *Journal[T]
+}
+
+// Tee returns a TeeEventSystem which records the events dispatched to es in j.
+func Tee[T comparable](es event.EventSystem[T], j *Journal[T]) *TeeEventSystem[T] {
+	return &TeeEventSystem[T]{EventSystem: es, journal: j}
+}
+
+// Journal returns the journal events are appended to.
+func (es *TeeEventSystem[T]) Journal() *Journal[T] {
+	return es.journal
+}
+
+// DispatchEvent implements the event.Dispatcher interface. The event is not
+// dispatched if it cannot be appended to the journal. Events dispatched by
+// Replay are not appended again.
+func (es *TeeEventSystem[T]) DispatchEvent(ctx context.Context, e event.Event[T]) error {
+	if !IsReplay(ctx) {
+		if _, err := es.journal.Append(e); err != nil {
+			return fmt.Errorf("journal: failed to append event: %w", err)
+		}
+	}
+	return es.EventSystem.DispatchEvent(ctx, e)
+}
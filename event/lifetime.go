@@ -0,0 +1,162 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ListenerRegistry adds and removes listeners.
+type ListenerRegistry[T comparable] interface {
+	ListenerAdder[T]
+	ListenerRemover
+}
+
+// onceListener removes itself from the registry before handling the first event.
+type onceListener[T comparable] struct {
+	Listener[T]
+	registry ListenerRegistry[T]
+	id       atomic.Int64
+	fired    atomic.Bool
+}
+
+// HandleEvent implements the Listener interface.
+func (l *onceListener[T]) HandleEvent(ctx context.Context, event Event[T]) error {
+	if !l.fired.CompareAndSwap(false, true) {
+		return nil
+	}
+	if id := ListenerID(l.id.Load()); id != 0 {
+		l.registry.RemoveListener(id)
+	}
+	return l.Listener.HandleEvent(ctx, event)
+}
+
+// AddOnceListener adds a listener which is removed after it handles its first event.
+func AddOnceListener[T comparable](r ListenerRegistry[T], listener Listener[T]) ListenerID {
+	l := &onceListener[T]{Listener: listener, registry: r}
+	id := r.AddListener(l)
+	l.id.Store(int64(id))
+	if l.fired.Load() {
+		// the event was handled by another goroutine before the ID was known
+		r.RemoveListener(id)
+	}
+	return id
+}
+
+// removalObserver is implemented by listeners which release resources when
+// they are removed from an event system of this package.
+type removalObserver interface {
+	removed()
+}
+
+// listenerRemoved notifies the listener that it has been removed.
+func listenerRemoved[T comparable](listener Listener[T]) {
+	if o, ok := listener.(removalObserver); ok {
+		o.removed()
+	}
+}
+
+// contextListener ignores events once its context is done.
+type contextListener[T comparable] struct {
+	Listener[T]
+	ctx  context.Context
+	id   atomic.Int64
+	stop func() bool // stops the removal when the context is done
+}
+
+// HandleEvent implements the Listener interface.
+func (l *contextListener[T]) HandleEvent(ctx context.Context, event Event[T]) error {
+	if l.ctx.Err() != nil {
+		return nil
+	}
+	return l.Listener.HandleEvent(ctx, event)
+}
+
+// removed releases the context registration of a listener removed by other means.
+func (l *contextListener[T]) removed() {
+	l.stop()
+}
+
+// AddContextListener adds a listener which is removed when the context is done.
+// The listener is removed from another goroutine, so the registry must be safe
+// for concurrent use, e.g. a ConcurrentEventSystem. If the listener is removed
+// earlier from an event system of this package, it no longer waits for the
+// context.
+func AddContextListener[T comparable](ctx context.Context, r ListenerRegistry[T], listener Listener[T]) ListenerID {
+	l := &contextListener[T]{Listener: listener, ctx: ctx}
+	l.stop = context.AfterFunc(ctx, func() {
+		if id := ListenerID(l.id.Load()); id != 0 {
+			r.RemoveListener(id)
+		}
+	})
+	id := r.AddListener(l)
+	l.id.Store(int64(id))
+	if ctx.Err() != nil {
+		// the context was done before the ID was known
+		r.RemoveListener(id)
+	}
+	return id
+}
+
+// ListenerGroup tracks listeners added to a registry so that they can be removed
+// at once, e.g. when a component shuts down.
+// It is safe for concurrent use if the registry is.
+type ListenerGroup[T comparable] struct {
+	registry ListenerRegistry[T]
+
+	mu  sync.Mutex
+	ids []ListenerID
+}
+
+// NewListenerGroup creates a new ListenerGroup instance for the registry.
+func NewListenerGroup[T comparable](r ListenerRegistry[T]) *ListenerGroup[T] {
+	return &ListenerGroup[T]{registry: r}
+}
+
+// Add adds a listener to the registry and the group.
+func (g *ListenerGroup[T]) Add(listener Listener[T]) ListenerID {
+	return g.track(g.registry.AddListener(listener))
+}
+
+// AddOnce adds a listener to the registry and the group which is removed after
+// it handles its first event. See AddOnceListener.
+func (g *ListenerGroup[T]) AddOnce(listener Listener[T]) ListenerID {
+	return g.track(AddOnceListener(g.registry, listener))
+}
+
+// AddContext adds a listener to the registry and the group which is removed when
+// the context is done. See AddContextListener.
+func (g *ListenerGroup[T]) AddContext(ctx context.Context, listener Listener[T]) ListenerID {
+	return g.track(AddContextListener(ctx, g.registry, listener))
+}
+
+func (g *ListenerGroup[T]) track(id ListenerID) ListenerID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ids = append(g.ids, id)
+	return id
+}
+
+// Len returns the number of listeners tracked by the group, including listeners
+// already removed from the registry by other means.
+func (g *ListenerGroup[T]) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.ids)
+}
+
+// RemoveAll removes all listeners of the group from the registry and returns the
+// number of listeners that were still registered.
+func (g *ListenerGroup[T]) RemoveAll() int {
+	g.mu.Lock()
+	ids := g.ids
+	g.ids = nil
+	g.mu.Unlock()
+	n := 0
+	for _, id := range ids {
+		if g.registry.RemoveListener(id) {
+			n++
+		}
+	}
+	return n
+}
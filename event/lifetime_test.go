@@ -0,0 +1,192 @@
+package event_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/event"
+)
+
+func TestAddOnceListener(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		es   event.EventSystem[int]
+	}{
+		{"Ordered", event.NewEventSystem[int](true)},
+		{"Unordered", event.NewEventSystem[int](false)},
+		{"Concurrent", event.NewConcurrentEventSystem[int]()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var onceCalls, otherCalls int
+			tt.es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				otherCalls++
+				return nil
+			}))
+			id := event.AddOnceListener(tt.es, event.Listen(1, func(ctx context.Context, e testEvent) error {
+				onceCalls++
+				return nil
+			}))
+			tt.es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				otherCalls++
+				return nil
+			}))
+			for i := 0; i < 3; i++ {
+				if err := tt.es.DispatchEvent(context.Background(), testEvent{eventType: 1}); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if onceCalls != 1 {
+				t.Errorf("Expected once listener to be called once, got %d", onceCalls)
+			}
+			if otherCalls != 6 {
+				t.Errorf("Expected other listeners to be called 6 times, got %d", otherCalls)
+			}
+			if tt.es.HasListener(id) {
+				t.Errorf("Expected once listener to be removed")
+			}
+		})
+	}
+}
+
+func TestAddOnceListenerConcurrent(t *testing.T) {
+	es := event.NewConcurrentEventSystem[int]()
+	var calls atomic.Int32
+	event.AddOnceListener(es, event.Listen(1, func(ctx context.Context, e testEvent) error {
+		calls.Add(1)
+		return nil
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected exactly one call, got %d", n)
+	}
+}
+
+// notifyingRegistry reports the IDs passed to RemoveListener on a channel.
+type notifyingRegistry struct {
+	event.EventSystem[int]
+	removed chan event.ListenerID
+}
+
+func (r notifyingRegistry) RemoveListener(id event.ListenerID) bool {
+	ok := r.EventSystem.RemoveListener(id)
+	r.removed <- id
+	return ok
+}
+
+func waitRemoved(t *testing.T, r notifyingRegistry) event.ListenerID {
+	t.Helper()
+	select {
+	case id := <-r.removed:
+		return id
+	case <-time.After(time.Second):
+		t.Fatalf("Expected context listener to be removed")
+		return 0
+	}
+}
+
+func TestAddContextListener(t *testing.T) {
+	t.Run("ContextDone", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		r := notifyingRegistry{es, make(chan event.ListenerID, 4)}
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		id := event.AddContextListener(ctx, r, event.Listen(1, func(ctx context.Context, e testEvent) error {
+			calls++
+			return nil
+		}))
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		cancel()
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+		if removed := waitRemoved(t, r); removed != id || es.HasListener(id) {
+			t.Errorf("Expected context listener %v to be removed, got %v", id, removed)
+		}
+	})
+
+	t.Run("ContextAlreadyDone", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		r := notifyingRegistry{es, make(chan event.ListenerID, 4)}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		id := event.AddContextListener(ctx, r, event.Listen(1, func(ctx context.Context, e testEvent) error {
+			t.Errorf("Unexpected call")
+			return nil
+		}))
+		if es.HasListener(id) {
+			t.Errorf("Expected context listener to be removed")
+		}
+		es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+	})
+
+	t.Run("RemovedEarly", func(t *testing.T) {
+		es := event.NewConcurrentEventSystem[int]()
+		r := notifyingRegistry{es, make(chan event.ListenerID, 4)}
+		ctx, cancel := context.WithCancel(context.Background())
+		handler := func(ctx context.Context, e testEvent) error { return nil }
+		early := event.AddContextListener(ctx, r, event.Listen(1, handler))
+		late := event.AddContextListener(ctx, r, event.Listen(1, handler))
+		if !es.RemoveListener(early) {
+			t.Fatalf("Failed to remove listener")
+		}
+		cancel()
+		// only the listener still registered is removed when the context is done
+		if removed := waitRemoved(t, r); removed != late {
+			t.Errorf("Expected listener %v to be removed, got %v", late, removed)
+		}
+		select {
+		case id := <-r.removed:
+			t.Errorf("Unexpected removal of listener %v", id)
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+}
+
+func TestListenerGroup(t *testing.T) {
+	es := event.NewConcurrentEventSystem[int]()
+	g := event.NewListenerGroup[int](es)
+	calls := 0
+	handler := func(ctx context.Context, e testEvent) error {
+		calls++
+		return nil
+	}
+	outside := es.AddListener(event.Listen(1, handler))
+	id1 := g.Add(event.Listen(1, handler))
+	id2 := g.Add(event.Listen(2, handler))
+	g.AddOnce(event.Listen(1, handler))
+	g.AddContext(context.Background(), event.Listen(1, handler))
+	if g.Len() != 4 {
+		t.Errorf("Expected 4 listeners in group, got %d", g.Len())
+	}
+
+	es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+	if calls != 4 {
+		t.Errorf("Expected 4 calls, got %d", calls)
+	}
+
+	// the once listener has already been removed
+	if n := g.RemoveAll(); n != 3 {
+		t.Errorf("Expected 3 listeners to be removed, got %d", n)
+	}
+	if es.HasListener(id1) || es.HasListener(id2) {
+		t.Errorf("Expected group listeners to be removed")
+	}
+	if !es.HasListener(outside) {
+		t.Errorf("Expected listener outside the group to remain")
+	}
+	if g.Len() != 0 {
+		t.Errorf("Expected empty group, got %d", g.Len())
+	}
+}
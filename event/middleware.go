@@ -0,0 +1,144 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// Handler handles an event on behalf of a listener.
+type Handler[T comparable] func(context.Context, Event[T]) error
+
+// Middleware wraps the Handler which calls a listener. It can run code before and
+// after the listener, replace the context, or skip the listener entirely.
+// The ID of the wrapped listener is available from the context via ListenerIDFromContext.
+type Middleware[T comparable] func(next Handler[T]) Handler[T]
+
+// MiddlewareUser registers middlewares that wrap every call to a listener.
+// Middlewares are applied in registration order, so the first registered
+// middleware is the outermost one.
+type MiddlewareUser[T comparable] interface {
+	Use(...Middleware[T])
+}
+
+type listenerIDKey struct{}
+
+func withListenerID(ctx context.Context, id ListenerID) context.Context {
+	return context.WithValue(ctx, listenerIDKey{}, id)
+}
+
+// ListenerIDFromContext returns the ID of the listener being called by a middleware.
+func ListenerIDFromContext(ctx context.Context) (ListenerID, bool) {
+	id, ok := ctx.Value(listenerIDKey{}).(ListenerID)
+	return id, ok
+}
+
+// chain returns a Handler which calls the listener through the middlewares.
+// Panics of the listener and of each middleware are converted into errors
+// before reaching the enclosing middlewares.
+func chain[T comparable](id ListenerID, listener Listener[T], middlewares []Middleware[T]) Handler[T] {
+	h := func(ctx context.Context, event Event[T]) error {
+		return handleEvent(ctx, id, listener, event)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = recoverMiddleware(id, middlewares[i], h)
+	}
+	return h
+}
+
+// recoverMiddleware returns a Handler which calls next through the middleware
+// and converts a panic of the middleware into a PanicError.
+func recoverMiddleware[T comparable](id ListenerID, m Middleware[T], next Handler[T]) Handler[T] {
+	return func(ctx context.Context, event Event[T]) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{ID: id, Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return m(next)(ctx, event)
+	}
+}
+
+// Logging returns a Middleware which logs every call to a listener with the given logger.
+// Successful calls are logged at debug level and failed calls at error level.
+func Logging[T comparable](logger *slog.Logger) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, event Event[T]) error {
+			start := time.Now()
+			err := next(ctx, event)
+			id, _ := ListenerIDFromContext(ctx)
+			attrs := []slog.Attr{
+				slog.Any("type", event.Typeof()),
+				slog.Int("listener", int(id)),
+				slog.Duration("elapsed", time.Since(start)),
+			}
+			if err != nil && !errors.Is(err, ErrStopPropagation) {
+				attrs = append(attrs, slog.Any("error", err))
+				logger.LogAttrs(ctx, slog.LevelError, "failed to handle event", attrs...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelDebug, "event handled", attrs...)
+			}
+			return err
+		}
+	}
+}
+
+// Timing returns a Middleware which reports the duration and result of every call
+// to a listener to the observe function, e.g. to record metrics.
+func Timing[T comparable](observe func(ctx context.Context, event Event[T], elapsed time.Duration, err error)) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, event Event[T]) error {
+			start := time.Now()
+			err := next(ctx, event)
+			observe(ctx, event, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// Retry returns a Middleware which calls a failing listener again, up to the given
+// number of attempts in total, waiting delay between attempts. It does not retry
+// if the listener stops propagation or panics, or if the context is done.
+func Retry[T comparable](attempts int, delay time.Duration) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, event Event[T]) error {
+			var err error
+			for i := 0; i < attempts || i == 0; i++ {
+				if i > 0 {
+					timer := time.NewTimer(delay)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return errors.Join(err, ctx.Err())
+					case <-timer.C:
+					}
+				}
+				if err = next(ctx, event); err == nil || !retryable(err) {
+					return err
+				}
+			}
+			return err
+		}
+	}
+}
+
+func retryable(err error) bool {
+	var pe *PanicError
+	return !errors.Is(err, ErrStopPropagation) && !errors.As(err, &pe)
+}
+
+// Trace returns a Middleware which starts a span for every call to a listener.
+// The start function returns the context passed to the listener and a function
+// which ends the span with the listener's result.
+func Trace[T comparable](start func(ctx context.Context, event Event[T]) (context.Context, func(error))) Middleware[T] {
+	return func(next Handler[T]) Handler[T] {
+		return func(ctx context.Context, event Event[T]) error {
+			ctx, end := start(ctx, event)
+			err := next(ctx, event)
+			end(err)
+			return err
+		}
+	}
+}
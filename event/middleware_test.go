@@ -0,0 +1,179 @@
+package event_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherd/core/event"
+)
+
+func TestMiddleware(t *testing.T) {
+	for name, es := range map[string]event.EventSystem[int]{
+		"EventSystem":           event.NewEventSystem[int](true),
+		"ConcurrentEventSystem": event.NewConcurrentEventSystem[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			trace := func(name string) event.Middleware[int] {
+				return func(next event.Handler[int]) event.Handler[int] {
+					return func(ctx context.Context, e event.Event[int]) error {
+						id, ok := event.ListenerIDFromContext(ctx)
+						if !ok {
+							t.Errorf("Expected listener ID in context")
+						}
+						calls = append(calls, name+":before:"+strconv.Itoa(int(id)))
+						err := next(ctx, e)
+						calls = append(calls, name+":after")
+						return err
+					}
+				}
+			}
+			es.(event.MiddlewareUser[int]).Use(trace("a"), trace("b"))
+			es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				calls = append(calls, "listener")
+				return nil
+			}))
+
+			if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := []string{"a:before:1", "b:before:1", "listener", "b:after", "a:after"}
+			if !slices.Equal(calls, want) {
+				t.Errorf("Expected calls %v, got %v", want, calls)
+			}
+		})
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	es := event.NewConcurrentEventSystem[int]()
+	var outer []error
+	es.Use(
+		func(next event.Handler[int]) event.Handler[int] {
+			return func(ctx context.Context, e event.Event[int]) error {
+				err := next(ctx, e)
+				outer = append(outer, err)
+				return err
+			}
+		},
+		func(next event.Handler[int]) event.Handler[int] {
+			return func(ctx context.Context, e event.Event[int]) error {
+				if id, _ := event.ListenerIDFromContext(ctx); id == 1 {
+					panic("middleware")
+				}
+				return next(ctx, e)
+			}
+		},
+	)
+	id1 := es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		return nil
+	}))
+	called := false
+	es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		called = true
+		return nil
+	}))
+
+	err := es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+	var pe *event.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected *event.PanicError, got %v", err)
+	}
+	if pe.ID != id1 || pe.Value != "middleware" {
+		t.Errorf("Expected panic of middleware wrapping listener %v, got %v", id1, pe)
+	}
+	if len(outer) != 2 || !errors.As(outer[0], &pe) {
+		t.Errorf("Expected enclosing middleware to receive *event.PanicError, got %v", outer)
+	}
+	if !called {
+		t.Errorf("Expected remaining listeners to be called after a middleware panicked")
+	}
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	es := event.NewConcurrentEventSystem[int]()
+	es.Use(event.Retry[int](3, time.Millisecond))
+	attempts := 0
+	es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}))
+	if err := es.DispatchEvent(context.Background(), testEvent{eventType: 1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+
+	panics := 0
+	es.AddListener(event.Listen(2, func(ctx context.Context, e testEvent) error {
+		panics++
+		panic("boom")
+	}))
+	var pe *event.PanicError
+	if err := es.DispatchEvent(context.Background(), testEvent{eventType: 2}); !errors.As(err, &pe) {
+		t.Errorf("Expected *event.PanicError, got %v", err)
+	}
+	if panics != 1 {
+		t.Errorf("Expected panicking listener not to be retried, got %d calls", panics)
+	}
+}
+
+func TestLoggingAndTimingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	var observed []time.Duration
+	var observedErr error
+
+	es := event.NewConcurrentEventSystem[int]()
+	es.Use(
+		event.Logging[int](logger),
+		event.Timing(func(ctx context.Context, e event.Event[int], elapsed time.Duration, err error) {
+			observed = append(observed, elapsed)
+			observedErr = err
+		}),
+	)
+	expectedErr := errors.New("test error")
+	es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		return expectedErr
+	}))
+	es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+
+	if len(observed) != 1 || !errors.Is(observedErr, expectedErr) {
+		t.Errorf("Expected one observation with error, got %v, %v", observed, observedErr)
+	}
+	out := buf.String()
+	for _, want := range []string{"failed to handle event", "type=1", "listener=1", "error=\"test error\""} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected log to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	type spanKey struct{}
+	es := event.NewConcurrentEventSystem[int]()
+	var ended bool
+	es.Use(event.Trace(func(ctx context.Context, e event.Event[int]) (context.Context, func(error)) {
+		return context.WithValue(ctx, spanKey{}, "span"), func(error) { ended = true }
+	}))
+	var span any
+	es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		span = ctx.Value(spanKey{})
+		return nil
+	}))
+	es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+	if span != "span" || !ended {
+		t.Errorf("Expected span to be propagated and ended, got %v, %v", span, ended)
+	}
+}
@@ -0,0 +1,149 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrStopPropagation is the sentinel error a listener returns (optionally wrapped)
+// to prevent the event from reaching the remaining listeners. It is not reported
+// as a dispatch error.
+var ErrStopPropagation = errors.New("stop propagation")
+
+// DispatchMode determines how errors returned by listeners affect dispatching.
+type DispatchMode int
+
+const (
+	// CollectAll calls every listener and joins all returned errors.
+	CollectAll DispatchMode = iota
+	// FailFast stops dispatching at the first listener that returns an error.
+	FailFast
+)
+
+// String returns a string representation of the DispatchMode.
+func (m DispatchMode) String() string {
+	switch m {
+	case CollectAll:
+		return "collect-all"
+	case FailFast:
+		return "fail-fast"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(m))
+	}
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (m DispatchMode) MarshalText() ([]byte, error) {
+	switch m {
+	case CollectAll, FailFast:
+		return []byte(m.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown dispatch mode %d", int(m))
+	}
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (m *DispatchMode) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "collect-all", "":
+		*m = CollectAll
+	case "fail-fast":
+		*m = FailFast
+	default:
+		return fmt.Errorf("unknown dispatch mode %q", text)
+	}
+	return nil
+}
+
+// DispatchReport describes how an event was dispatched to listeners.
+type DispatchReport struct {
+	// Ran holds the IDs of the listeners that handled the event, in call order.
+	Ran []ListenerID
+
+	// StoppedBy is the ID of the listener that stopped propagation, or 0 if
+	// propagation was not stopped.
+	StoppedBy ListenerID
+}
+
+// ReportDispatcher dispatches events and reports which listeners handled them.
+type ReportDispatcher[T comparable] interface {
+	DispatchEventReport(context.Context, Event[T]) (DispatchReport, error)
+}
+
+// PanicError is the error reported when a listener, or a middleware wrapping
+// it, panics while handling an event.
+type PanicError struct {
+	// ID is the ID of the panicking listener, or of the listener wrapped by the
+	// panicking middleware.
+	ID ListenerID
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("listener %d panicked: %v", e.ID, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// handleEvent calls the listener and converts a panic into a PanicError.
+func handleEvent[T comparable](ctx context.Context, id ListenerID, listener Listener[T], event Event[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{ID: id, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return listener.HandleEvent(ctx, event)
+}
+
+// dispatch dispatches the event to n listeners returned by at in order, passing
+// each call through the middlewares. If report is nil, the called listeners are
+// not recorded.
+func dispatch[T comparable](
+	ctx context.Context,
+	event Event[T],
+	mode DispatchMode,
+	middlewares []Middleware[T],
+	n int,
+	at func(int) (ListenerID, Listener[T]),
+	report *DispatchReport,
+) error {
+	var errs []error
+	for i := 0; i < n; i++ {
+		id, listener := at(i)
+		var err error
+		if len(middlewares) == 0 {
+			err = handleEvent(ctx, id, listener, event)
+		} else {
+			err = chain(id, listener, middlewares)(withListenerID(ctx, id), event)
+		}
+		if report != nil {
+			report.Ran = append(report.Ran, id)
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, ErrStopPropagation) {
+			if report != nil {
+				report.StoppedBy = id
+			}
+			break
+		}
+		errs = append(errs, err)
+		if mode == FailFast {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
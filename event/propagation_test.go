@@ -0,0 +1,148 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/event"
+)
+
+func TestStopPropagation(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		es   event.EventSystem[int]
+	}{
+		{"EventSystem", event.NewEventSystem[int](true)},
+		{"ConcurrentEventSystem", event.NewConcurrentEventSystem[int]()},
+		{"AsyncConcurrentEventSystem", event.NewConcurrentEventSystem[int](event.WithAsync(1, 1))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var called []int
+			id1 := tt.es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				called = append(called, 1)
+				return nil
+			}))
+			id2 := tt.es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				called = append(called, 2)
+				return fmt.Errorf("handled: %w", event.ErrStopPropagation)
+			}))
+			tt.es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+				called = append(called, 3)
+				return nil
+			}))
+
+			report, err := tt.es.(event.ReportDispatcher[int]).DispatchEventReport(context.Background(), testEvent{eventType: 1})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if want := []int{1, 2}; !slices.Equal(called, want) {
+				t.Errorf("Expected call order %v, got %v", want, called)
+			}
+			if want := []event.ListenerID{id1, id2}; !slices.Equal(report.Ran, want) {
+				t.Errorf("Expected report.Ran %v, got %v", want, report.Ran)
+			}
+			if report.StoppedBy != id2 {
+				t.Errorf("Expected report.StoppedBy %v, got %v", id2, report.StoppedBy)
+			}
+		})
+	}
+}
+
+func TestDispatchMode(t *testing.T) {
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+	for _, tt := range []struct {
+		mode    event.DispatchMode
+		wantRan int
+		wantErr []error
+		notErr  []error
+	}{
+		{event.CollectAll, 3, []error{err1, err2}, nil},
+		{event.FailFast, 1, []error{err1}, []error{err2}},
+	} {
+		for name, es := range map[string]event.EventSystem[int]{
+			"EventSystem":           event.NewEventSystemWithMode[int](true, tt.mode),
+			"ConcurrentEventSystem": event.NewConcurrentEventSystem[int](event.WithDispatchMode(tt.mode)),
+		} {
+			t.Run(tt.mode.String()+"/"+name, func(t *testing.T) {
+				es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+					return err1
+				}))
+				es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+					return nil
+				}))
+				es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+					return err2
+				}))
+				report, err := es.(event.ReportDispatcher[int]).DispatchEventReport(context.Background(), testEvent{eventType: 1})
+				if len(report.Ran) != tt.wantRan {
+					t.Errorf("Expected %d listeners to run, got %d", tt.wantRan, len(report.Ran))
+				}
+				for _, want := range tt.wantErr {
+					if !errors.Is(err, want) {
+						t.Errorf("Expected error %v in %v", want, err)
+					}
+				}
+				for _, unwanted := range tt.notErr {
+					if errors.Is(err, unwanted) {
+						t.Errorf("Unexpected error %v in %v", unwanted, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPanicRecovery(t *testing.T) {
+	es := event.NewEventSystem[int](true)
+	sentinel := errors.New("sentinel")
+	id1 := es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		panic(sentinel)
+	}))
+	called := false
+	es.AddListener(event.Listen(1, func(ctx context.Context, e testEvent) error {
+		called = true
+		return nil
+	}))
+
+	err := es.DispatchEvent(context.Background(), testEvent{eventType: 1})
+	var pe *event.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected *event.PanicError, got %v", err)
+	}
+	if pe.ID != id1 {
+		t.Errorf("Expected panic from listener %v, got %v", id1, pe.ID)
+	}
+	if len(pe.Stack) == 0 {
+		t.Errorf("Expected stack trace")
+	}
+	if !errors.Is(err, sentinel) {
+		t.Errorf("Expected panic value to be unwrapped")
+	}
+	if !called {
+		t.Errorf("Expected remaining listeners to be called after panic")
+	}
+}
+
+func TestDispatchModeText(t *testing.T) {
+	for _, m := range []event.DispatchMode{event.CollectAll, event.FailFast} {
+		text, err := m.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText failed: %v", err)
+		}
+		var got event.DispatchMode
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) failed: %v", text, err)
+		}
+		if got != m {
+			t.Errorf("Expected %v, got %v", m, got)
+		}
+	}
+	var m event.DispatchMode
+	if err := m.UnmarshalText([]byte("first")); err == nil {
+		t.Errorf("Expected error for unknown dispatch mode")
+	}
+}
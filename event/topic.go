@@ -0,0 +1,153 @@
+package event
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// TopicSeparator separates the segments of a hierarchical topic, e.g. "user.login".
+	TopicSeparator = "."
+	// TopicWildcardOne matches exactly one segment of a topic, e.g. "user.*" matches
+	// "user.login" but neither "user" nor "user.login.failed".
+	TopicWildcardOne = "*"
+	// TopicWildcardAny matches zero or more segments of a topic, e.g. "user.#" matches
+	// "user", "user.login" and "user.login.failed".
+	TopicWildcardAny = "#"
+)
+
+// NewTopicEventSystem creates a new ConcurrentEventSystem for hierarchical string
+// event types. The event type of a listener is a topic pattern whose segments,
+// separated by TopicSeparator, may be TopicWildcardOne or TopicWildcardAny.
+// An event is dispatched to every listener whose pattern matches its type,
+// ordered by priority and then by registration order.
+func NewTopicEventSystem[T ~string](opts ...ConcurrentOption) ConcurrentEventSystem[T] {
+	return newConcurrentEventSystem[T](&topicRegistry[T]{root: newTopicNode[T](nil, "")}, opts)
+}
+
+// MatchTopic reports whether the topic matches the pattern.
+func MatchTopic(pattern, topic string) bool {
+	return matchSegments(splitTopic(pattern), splitTopic(topic))
+}
+
+func splitTopic[T ~string](topic T) []string {
+	return strings.Split(string(topic), TopicSeparator)
+}
+
+func matchSegments(pattern, topic []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case TopicWildcardAny:
+			for i := 0; i <= len(topic); i++ {
+				if matchSegments(pattern[1:], topic[i:]) {
+					return true
+				}
+			}
+			return false
+		case TopicWildcardOne:
+			if len(topic) == 0 {
+				return false
+			}
+		default:
+			if len(topic) == 0 || topic[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, topic = pattern[1:], topic[1:]
+	}
+	return len(topic) == 0
+}
+
+type topicNode[T ~string] struct {
+	parent    *topicNode[T]
+	segment   string
+	children  map[string]*topicNode[T]
+	listeners []priorityListener[T] // copy-on-write, sorted
+}
+
+func newTopicNode[T ~string](parent *topicNode[T], segment string) *topicNode[T] {
+	return &topicNode[T]{
+		parent:   parent,
+		segment:  segment,
+		children: make(map[string]*topicNode[T]),
+	}
+}
+
+// topicRegistry matches listeners by topic patterns stored in a trie of segments.
+type topicRegistry[T ~string] struct {
+	root *topicNode[T]
+}
+
+func (r *topicRegistry[T]) add(pattern T, l priorityListener[T]) {
+	n := r.root
+	for _, segment := range splitTopic(pattern) {
+		child, ok := n.children[segment]
+		if !ok {
+			child = newTopicNode(n, segment)
+			n.children[segment] = child
+		}
+		n = child
+	}
+	n.listeners = insertListener(n.listeners, l)
+}
+
+func (r *topicRegistry[T]) remove(pattern T, id ListenerID) {
+	n := r.root
+	for _, segment := range splitTopic(pattern) {
+		if n = n.children[segment]; n == nil {
+			return
+		}
+	}
+	n.listeners = deleteListener(n.listeners, id)
+	// prune empty nodes
+	for n.parent != nil && len(n.listeners) == 0 && len(n.children) == 0 {
+		delete(n.parent.children, n.segment)
+		n = n.parent
+	}
+}
+
+func (r *topicRegistry[T]) match(topic T) []priorityListener[T] {
+	var nodes []*topicNode[T]
+	r.root.collect(splitTopic(topic), &nodes)
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0].listeners
+	}
+	var listeners []priorityListener[T]
+	for _, n := range nodes {
+		listeners = append(listeners, n.listeners...)
+	}
+	slices.SortFunc(listeners, comparePriorityListeners[T])
+	return listeners
+}
+
+// collect appends the nodes with listeners whose patterns match the topic to nodes.
+func (n *topicNode[T]) collect(topic []string, nodes *[]*topicNode[T]) {
+	if len(topic) == 0 {
+		if len(n.listeners) > 0 && !slices.Contains(*nodes, n) {
+			*nodes = append(*nodes, n)
+		}
+		// trailing "#" matches zero segments
+		if child := n.children[TopicWildcardAny]; child != nil {
+			child.collect(topic, nodes)
+		}
+		return
+	}
+	if child := n.children[topic[0]]; child != nil {
+		child.collect(topic[1:], nodes)
+	}
+	if topic[0] != TopicWildcardOne {
+		if child := n.children[TopicWildcardOne]; child != nil {
+			child.collect(topic[1:], nodes)
+		}
+	}
+	if topic[0] != TopicWildcardAny {
+		if child := n.children[TopicWildcardAny]; child != nil {
+			for i := 0; i <= len(topic); i++ {
+				child.collect(topic[i:], nodes)
+			}
+		}
+	}
+}
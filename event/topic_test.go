@@ -0,0 +1,138 @@
+package event_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/gopherd/core/event"
+)
+
+type topicEvent struct {
+	topic string
+}
+
+func (e topicEvent) Typeof() string {
+	return e.topic
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"user.login", "user.login", true},
+		{"user.login", "user.logout", false},
+		{"user.*", "user.login", true},
+		{"user.*", "user", false},
+		{"user.*", "user.login.failed", false},
+		{"*.login", "user.login", true},
+		{"user.#", "user", true},
+		{"user.#", "user.login", true},
+		{"user.#", "user.login.failed", true},
+		{"user.#", "admin.login", false},
+		{"#", "user.login", true},
+		{"#.failed", "user.login.failed", true},
+		{"#.failed", "failed", true},
+		{"user.#.failed", "user.failed", true},
+		{"user.#.failed", "user.login.x.failed", true},
+		{"user.#.failed", "user.login", false},
+		{"*.*", "user", false},
+	}
+	for _, tt := range tests {
+		if got := event.MatchTopic(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestTopicEventSystem(t *testing.T) {
+	es := event.NewTopicEventSystem[string]()
+	var called []string
+	listen := func(pattern string) event.ListenerID {
+		return es.AddListener(event.Listen(pattern, func(ctx context.Context, e topicEvent) error {
+			called = append(called, pattern)
+			return nil
+		}))
+	}
+	listen("user.login")
+	listen("user.*")
+	hashID := listen("user.#")
+	listen("#")
+	listen("*.logout")
+	listen("#.#")
+	es.AddListenerWithPriority(event.Listen("#.login", func(ctx context.Context, e topicEvent) error {
+		called = append(called, "#.login!")
+		return nil
+	}), 1)
+
+	tests := []struct {
+		topic string
+		want  []string
+	}{
+		{"user.login", []string{"#.login!", "user.login", "user.*", "user.#", "#", "#.#"}},
+		{"user.logout", []string{"user.*", "user.#", "#", "*.logout", "#.#"}},
+		{"user", []string{"user.#", "#", "#.#"}},
+		{"admin.login.failed", []string{"#", "#.#"}},
+	}
+	for _, tt := range tests {
+		called = nil
+		if err := es.DispatchEvent(context.Background(), topicEvent{tt.topic}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !slices.Equal(called, tt.want) {
+			t.Errorf("DispatchEvent(%q) called %v, want %v", tt.topic, called, tt.want)
+		}
+	}
+
+	if !es.RemoveListener(hashID) {
+		t.Fatalf("Failed to remove listener")
+	}
+	called = nil
+	es.DispatchEvent(context.Background(), topicEvent{"user"})
+	if want := []string{"#", "#.#"}; !slices.Equal(called, want) {
+		t.Errorf("DispatchEvent after removal called %v, want %v", called, want)
+	}
+}
+
+func TestCatchAllListener(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		es   event.ConcurrentEventSystem[string]
+	}{
+		{"Exact", event.NewConcurrentEventSystem[string]()},
+		{"Topic", event.NewTopicEventSystem[string]()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var called []string
+			tt.es.AddListener(event.Listen("a", func(ctx context.Context, e topicEvent) error {
+				called = append(called, "a")
+				return nil
+			}))
+			id := tt.es.AddCatchAllListener(event.Listen("", func(ctx context.Context, e event.Event[string]) error {
+				called = append(called, "all:"+e.Typeof())
+				return nil
+			}), 0)
+			tt.es.AddCatchAllListener(event.Listen("", func(ctx context.Context, e event.Event[string]) error {
+				called = append(called, "first:"+e.Typeof())
+				return nil
+			}), 1)
+
+			tt.es.DispatchEvent(context.Background(), topicEvent{"a"})
+			tt.es.DispatchEvent(context.Background(), topicEvent{"b"})
+			want := []string{"first:a", "a", "all:a", "first:b", "all:b"}
+			if !slices.Equal(called, want) {
+				t.Errorf("Expected calls %v, got %v", want, called)
+			}
+
+			if !tt.es.RemoveListener(id) || tt.es.HasListener(id) {
+				t.Fatalf("Failed to remove catch-all listener")
+			}
+			called = nil
+			tt.es.DispatchEvent(context.Background(), topicEvent{"b"})
+			if want := []string{"first:b"}; !slices.Equal(called, want) {
+				t.Errorf("Expected calls %v, got %v", want, called)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+// Package codec implements the codecs shared by the event and history packages,
+// which re-export them.
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Codec marshals and unmarshals values.
+type Codec interface {
+	// Name returns the name of the codec, e.g. "json".
+	Name() string
+	// Marshal returns the encoding of v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is a Codec using encoding/json.
+type JSON struct{}
+
+// Name implements the Codec interface.
+func (JSON) Name() string { return "json" }
+
+// Marshal implements the Codec interface.
+func (JSON) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements the Codec interface.
+func (JSON) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// Gob is a Codec using encoding/gob. Each value is encoded as a
+// self-describing gob stream.
+type Gob struct{}
+
+// Name implements the Codec interface.
+func (Gob) Name() string { return "gob" }
+
+// Marshal implements the Codec interface.
+func (Gob) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements the Codec interface.
+func (Gob) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ErrUnsupportedType is the error returned when a codec cannot encode or decode a type.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// Binary is a compact Codec which encodes values without field names or type
+// information. Both sides must therefore agree on the exact type.
+//
+// Integers are encoded as varints, floats as fixed-size little-endian bits, and
+// strings, slices and maps are prefixed by their length. Structs are encoded as
+// their exported fields in declaration order, and pointers are prefixed by a
+// presence byte. Values implementing encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler (e.g. time.Time) are encoded as length-prefixed bytes.
+// Empty slices and maps are decoded as nil. Interfaces, channels, functions and complex
+// numbers are not supported.
+type Binary struct{}
+
+// Name implements the Codec interface.
+func (Binary) Name() string { return "binary" }
+
+// Marshal implements the Codec interface.
+func (Binary) Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer {
+		return nil, fmt.Errorf("%w: nil value", ErrUnsupportedType)
+	}
+	return appendBinary(nil, rv)
+}
+
+// Unmarshal implements the Codec interface.
+func (Binary) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: unmarshal into non-pointer %T", ErrUnsupportedType, v)
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	r := bytes.NewReader(data)
+	if err := readBinary(r, rv); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("binary codec: %d trailing bytes", r.Len())
+	}
+	return nil
+}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// isBinaryMarshaler reports whether values of the non-pointer type t are encoded
+// with their own MarshalBinary and UnmarshalBinary methods.
+func isBinaryMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(binaryMarshalerType) && pt.Implements(binaryUnmarshalerType)
+}
+
+func appendBinary(b []byte, v reflect.Value) ([]byte, error) {
+	if isBinaryMarshaler(v.Type()) {
+		if !v.CanAddr() {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			v = p.Elem()
+		}
+		data, err := v.Addr().Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = binary.AppendUvarint(b, uint64(len(data)))
+		return append(b, data...), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(b, v.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		b = binary.AppendUvarint(b, uint64(v.Len()))
+		return append(b, v.String()...), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b = binary.AppendUvarint(b, uint64(v.Len()))
+			return append(b, v.Bytes()...), nil
+		}
+		b = binary.AppendUvarint(b, uint64(v.Len()))
+		fallthrough
+	case reflect.Array:
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if b, err = appendBinary(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		b = binary.AppendUvarint(b, uint64(v.Len()))
+		var err error
+		iter := v.MapRange()
+		for iter.Next() {
+			if b, err = appendBinary(b, iter.Key()); err != nil {
+				return nil, err
+			}
+			if b, err = appendBinary(b, iter.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Struct:
+		var err error
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if b, err = appendBinary(b, v.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return append(b, 0), nil
+		}
+		return appendBinary(append(b, 1), v.Elem())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+}
+
+func readBinary(r *bytes.Reader, v reflect.Value) error {
+	if isBinaryMarshaler(v.Type()) {
+		data, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(data)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		c, err := r.ReadByte()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		v.SetBool(c != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := binary.ReadVarint(r)
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if v.OverflowInt(x) {
+			return fmt.Errorf("binary codec: value %d overflows %s", x, v.Type())
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if v.OverflowUint(x) {
+			return fmt.Errorf("binary codec: value %d overflows %s", x, v.Type())
+		}
+		v.SetUint(x)
+	case reflect.Float32:
+		var buf [4]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))))
+	case reflect.Float64:
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(buf[:])))
+	case reflect.String:
+		data, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		v.SetString(string(data))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(data)
+			return nil
+		}
+		n, err := readLen(r, isEmptyBinary(v.Type().Elem()))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			if err := readBinary(r, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := readBinary(r, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		t := v.Type()
+		n, err := readLen(r, isEmptyBinary(t.Key()) && isEmptyBinary(t.Elem()))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.MakeMapWithSize(t, n))
+		for i := 0; i < n; i++ {
+			key := reflect.New(t.Key()).Elem()
+			if err := readBinary(r, key); err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := readBinary(r, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := readBinary(r, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		c, err := r.ReadByte()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if c == 0 {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return readBinary(r, v.Elem())
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
+
+// maxEmptyLen is the maximum length of a decoded slice or map whose elements
+// are encoded as zero bytes, which the remaining input cannot bound.
+const maxEmptyLen = 1 << 20
+
+// readLen reads the length of a sequence. If empty is false, every element
+// occupies at least one byte, so the length cannot exceed the remaining input.
+func readLen(r *bytes.Reader, empty bool) (int, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	if empty {
+		if n > maxEmptyLen {
+			return 0, fmt.Errorf("binary codec: length %d of empty elements exceeds %d", n, maxEmptyLen)
+		}
+	} else if n > uint64(r.Len()) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+// isEmptyBinary reports whether values of type t are encoded as zero bytes.
+func isEmptyBinary(t reflect.Type) bool {
+	if isBinaryMarshaler(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() == 0 || isEmptyBinary(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && !isEmptyBinary(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readLen(r, false)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
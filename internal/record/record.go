@@ -0,0 +1,95 @@
+// Package record implements the framing of the journals of the event and
+// history packages. Each record is stored as a 4-byte big-endian length, a
+// 4-byte CRC-32 (Castagnoli) checksum and the data.
+//
+// It also implements the plain frames of envelopes and of the event bus, which
+// are stored as a 4-byte big-endian length and the data.
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// HeaderSize is the size in bytes of the header preceding the data of a record.
+const HeaderSize = 8
+
+// ErrCorrupt is the error returned when a record fails its checksum or its
+// length exceeds the maximum size.
+var ErrCorrupt = errors.New("corrupt record")
+
+// ErrTooLarge is the error returned when a frame is larger than the maximum size.
+var ErrTooLarge = errors.New("frame too large")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Append appends the record of the data to b and returns the extended buffer.
+func Append(b, data []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	b = binary.BigEndian.AppendUint32(b, crc32.Checksum(data, crcTable))
+	return append(b, data...)
+}
+
+// Read reads the next record from r and returns its data. It returns io.EOF at
+// a clean end of r, io.ErrUnexpectedEOF for a truncated record and ErrCorrupt
+// for a damaged record or one whose data is larger than maxSize. The length is
+// checked before the data is allocated.
+func Read(r io.Reader, maxSize int) ([]byte, error) {
+	var header [HeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[0:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, ErrCorrupt
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if crc32.Checksum(data, crcTable) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, ErrCorrupt
+	}
+	return data, nil
+}
+
+// WriteFrame writes the data to w prefixed by its length as a 4-byte big-endian
+// integer. It returns ErrTooLarge if the data is larger than maxSize.
+func WriteFrame(w io.Writer, data []byte, maxSize int) error {
+	if len(data) > maxSize {
+		return fmt.Errorf("%w: %d bytes", ErrTooLarge, len(data))
+	}
+	buf := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadFrame reads a frame written by WriteFrame from r and returns its data. It
+// returns io.EOF at a clean end of r, io.ErrUnexpectedEOF for a truncated frame
+// and ErrTooLarge for a frame whose data is larger than maxSize.
+func ReadFrame(r io.Reader, maxSize int) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
@@ -6,6 +6,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -247,8 +248,9 @@ func (s *BaseService[T]) Shutdown(ctx context.Context) error {
 }
 
 type runOptions struct {
-	encoder encoding.Encoder
-	decoder encoding.Decoder
+	encoder  encoding.Encoder
+	decoder  encoding.Decoder
+	exitCode func(error) int
 }
 
 // apply applies the options to the given options.
@@ -275,10 +277,45 @@ func WithDecoder(decoder encoding.Decoder) RunOption {
 	}
 }
 
+// WithExitCode sets the function which maps an error returned by the service to
+// the exit code of the process. Errors created by errkit.NewExitError always exit
+// with their own code. By default, other errors exit with code 1.
+func WithExitCode(exitCode func(error) int) RunOption {
+	return func(o *runOptions) {
+		o.exitCode = exitCode
+	}
+}
+
+// ExitCodeByErrno returns a function for WithExitCode which maps the errkit
+// error code of an error to an exit code. Errors with unmapped codes exit with
+// code 1.
+func ExitCodeByErrno(codes map[int]int) func(error) int {
+	return func(err error) int {
+		if code, ok := codes[errkit.Errno(err)]; ok {
+			return code
+		}
+		return 1
+	}
+}
+
+// exitCodeOf returns the exit code of the process for the error returned by the service.
+func (o *runOptions) exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	if code, ok := errkit.ExitCode(err); ok {
+		return code
+	}
+	if o.exitCode != nil {
+		return o.exitCode(err)
+	}
+	return 1
+}
+
 // Run is a convenience function for running a service with a default configuration.
 // It creates and runs a BaseService with an empty context.
 // This function always exits the program:
-// - It exits with the error code if an error occurs.
+// - It exits with the error code if an error occurs, see WithExitCode.
 // - It exits with code 0 if the service runs successfully.
 // It is recommended to use this function unless you need to customize the Service
 // or want to prevent the program from exiting.
@@ -289,13 +326,7 @@ func Run(opts ...RunOption) {
 	s := NewBaseService(Config[context]{Context: context{}})
 	s.encoder = o.encoder
 	s.decoder = o.decoder
-	if err := RunService(s); err != nil {
-		if exitCode, ok := errkit.ExitCode(err); ok {
-			os.Exit(exitCode)
-		}
-		os.Exit(1)
-	}
-	os.Exit(0)
+	os.Exit(o.exitCodeOf(RunService(s)))
 }
 
 // RunService starts and manages the lifecycle of the given service.
@@ -303,15 +334,24 @@ func Run(opts ...RunOption) {
 // This function returns any error encountered during the service lifecycle.
 // Use this function if you need to run a custom Service implementation or
 // if you want to handle errors without exiting the program.
-func RunService(s Service) error {
+//
+// A panic in any lifecycle phase is recovered and returned as a
+// *component.PanicError. The service is still shut down and uninitialized, so
+// components which have already been started are shut down and components
+// which have already been initialized are uninitialized.
+func RunService(s Service) (err error) {
 	defer func() {
 		s.Logger().Info("uninitializing service")
-		if err := s.Uninit(context.Background()); err != nil {
-			s.Logger().Error("failed to uninitialize service", slog.Any("error", err))
+		if uerr := component.Recover(context.Background(), "uninit", s.Uninit); uerr != nil {
+			s.Logger().Error("failed to uninitialize service", slog.Any("error", uerr))
+			if isPanic(uerr) {
+				// panics are reported even if the service has failed already
+				err = errkit.Join(err, uerr)
+			}
 		}
 		s.Logger().Info("service exited")
 	}()
-	if err := s.Init(context.Background()); err != nil {
+	if err := component.Recover(context.Background(), "init", s.Init); err != nil {
 		if _, ok := errkit.ExitCode(err); ok {
 			return err
 		}
@@ -322,13 +362,23 @@ func RunService(s Service) error {
 	s.Logger().Info("starting service")
 	defer func() {
 		s.Logger().Info("shutting down service")
-		if err := s.Shutdown(context.Background()); err != nil {
-			s.Logger().Error("failed to shutdown service", slog.Any("error", err))
+		if serr := component.Recover(context.Background(), "shutdown", s.Shutdown); serr != nil {
+			s.Logger().Error("failed to shutdown service", slog.Any("error", serr))
+			if isPanic(serr) {
+				// panics are reported even if the service has failed already
+				err = errkit.Join(err, serr)
+			}
 		}
 	}()
-	err := s.Start(context.Background())
+	err = component.Recover(context.Background(), "start", s.Start)
 	if err != nil {
 		s.Logger().Error("failed to start service", slog.Any("error", err))
 	}
 	return err
 }
+
+// isPanic reports whether err contains a *component.PanicError.
+func isPanic(err error) bool {
+	var pe *component.PanicError
+	return errors.As(err, &pe)
+}
@@ -584,6 +584,121 @@ func TestRunService(t *testing.T) {
 	}
 }
 
+func TestRunServicePanic(t *testing.T) {
+	for _, phase := range []string{"init", "start", "shutdown", "uninit"} {
+		t.Run(phase, func(t *testing.T) {
+			var called []string
+			fn := func(p string) func(context.Context) error {
+				return func(context.Context) error {
+					called = append(called, p)
+					if p == phase {
+						panic("boom")
+					}
+					return nil
+				}
+			}
+			s := &mockService{
+				initFunc:     fn("init"),
+				startFunc:    fn("start"),
+				shutdownFunc: fn("shutdown"),
+				uninitFunc:   fn("uninit"),
+				logger:       slog.Default(),
+			}
+			err := RunService(s)
+			var pe *component.PanicError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Expected *component.PanicError, got %v", err)
+			}
+			if pe.Phase != phase || pe.Value != "boom" || len(pe.Stack) == 0 {
+				t.Errorf("Unexpected panic error: %+v", pe)
+			}
+			want := "init,start,shutdown,uninit"
+			if phase == "init" {
+				want = "init,uninit"
+			}
+			if got := strings.Join(called, ","); got != want {
+				t.Errorf("Expected phases %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+type panickingComponent struct {
+	mockComponent
+}
+
+func (c *panickingComponent) Start(ctx context.Context) error {
+	panic(errors.New("start failed badly"))
+}
+
+func TestRunServiceComponentPanic(t *testing.T) {
+	group := component.NewGroup()
+	first := &mockComponent{}
+	second := &panickingComponent{}
+	container := &mockService{logger: slog.Default()}
+	first.Setup(container, &component.Config{Name: "First", UUID: "first"}, false)
+	second.Setup(container, &component.Config{Name: "Second", UUID: "second"}, false)
+	group.AddComponent("first", first)
+	group.AddComponent("second", second)
+	s := &mockService{
+		initFunc:     group.Init,
+		startFunc:    group.Start,
+		shutdownFunc: group.Shutdown,
+		uninitFunc:   group.Uninit,
+		logger:       slog.Default(),
+	}
+
+	err := RunService(s)
+	var pe *component.PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Expected *component.PanicError, got %v", err)
+	}
+	if pe.Component != second.String() || pe.Phase != "start" {
+		t.Errorf("Unexpected panic error: %v", pe)
+	}
+	if !strings.Contains(err.Error(), "component "+second.String()+" panicked during start: start failed badly") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if !first.shutdownCalled || !first.uninitCalled {
+		t.Errorf("Expected started component to be shut down and uninitialized")
+	}
+	if second.shutdownCalled || !second.uninitCalled {
+		t.Errorf("Expected panicking component to be uninitialized only")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	errNotFound := errkit.New(404, errors.New("not found"))
+	tests := []struct {
+		name     string
+		exitCode func(error) int
+		err      error
+		want     int
+	}{
+		{"nil", nil, nil, 0},
+		{"default", nil, errors.New("failed"), 1},
+		{"exit error", ExitCodeByErrno(map[int]int{404: 4}), errkit.NewExitError(3), 3},
+		{"mapped errno", ExitCodeByErrno(map[int]int{404: 4}), errNotFound, 4},
+		{"unmapped errno", ExitCodeByErrno(map[int]int{500: 5}), errNotFound, 1},
+		{"custom", func(err error) int {
+			var pe *component.PanicError
+			if errors.As(err, &pe) {
+				return 70
+			}
+			return 1
+		}, &component.PanicError{Phase: "start", Value: "boom"}, 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o runOptions
+			o.apply([]RunOption{WithExitCode(tt.exitCode)})
+			if got := o.exitCodeOf(tt.err); got != tt.want {
+				t.Errorf("exitCodeOf(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
 // mockComponent is a mock implementation of component.Component for testing
 type mockComponent struct {
 	component.BaseComponent[struct{}]